@@ -0,0 +1,143 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// Key sizes of the byte-keyed convenience trees.
+const (
+	AddressKeySize = 20 // Size of an Ethereum address, giving a depth of 160.
+	HashKeySize    = 32 // Size of a 256-bit hash, giving a depth of 256.
+)
+
+// ByteKeyedTree is a sparse Merkle tree whose leaves are addressed by byte
+// keys instead of integer indices. The bits of a fixed-size key, most
+// significant first, are its path from the root. Variable-length keys, such
+// as strings or UTXO identifiers, are mapped to the index DeriveIndexBig
+// derives from them in KeyDomainBytes.
+type ByteKeyedTree struct {
+	tree    *SparseMerkleTree
+	keySize int // Size of the keys in bytes, or 0 for variable-length keys.
+}
+
+// NewAddressTree creates a Poseidon tree of depth 160 keyed by Ethereum
+// addresses, with Poseidon(0) as the zero leaf.
+func NewAddressTree() *ByteKeyedTree {
+	return NewByteKeyedTree(AddressKeySize, defaultZeroLeaf())
+}
+
+// NewHashKeyedTree creates a Poseidon tree of depth 256 keyed by 256-bit
+// hashes, with Poseidon(0) as the zero leaf.
+func NewHashKeyedTree() *ByteKeyedTree {
+	return NewByteKeyedTree(HashKeySize, defaultZeroLeaf())
+}
+
+// NewByteKeyedTree creates a tree keyed by keys of keySize bytes. The tree
+// stores a subtree holding a single leaf as one node, so memory grows with the
+// number of leaves rather than with depth times the number of leaves; roots
+// and proofs are those of an uncompressed tree.
+func NewByteKeyedTree(keySize int, zeroLeaf *big.Int) *ByteKeyedTree {
+	tree := NewSparseMerkleTree(keySize*8, zeroLeaf)
+	tree.compressed = true
+	return &ByteKeyedTree{tree: tree, keySize: keySize}
+}
+
+// NewVariableKeyTree creates a tree of depth 256 keyed by byte strings of any
+// length, whose indices are derived from the keys in KeyDomainBytes, as
+// InsertHashed derives them. Like NewByteKeyedTree, it compresses subtrees
+// holding a single leaf.
+func NewVariableKeyTree(zeroLeaf *big.Int) *ByteKeyedTree {
+	tree := NewSparseMerkleTree(HashKeySize*8, zeroLeaf)
+	tree.compressed = true
+	return &ByteKeyedTree{tree: tree}
+}
+
+// defaultZeroLeaf returns Poseidon(0), the zero leaf used by the
+// preconfigured trees.
+func defaultZeroLeaf() *big.Int {
+	zeroLeaf, _ := poseidon.Hash([]*big.Int{big.NewInt(0)})
+	return zeroLeaf
+}
+
+// Tree returns the underlying sparse Merkle tree.
+func (t *ByteKeyedTree) Tree() *SparseMerkleTree {
+	return t.tree
+}
+
+// Root returns the root hash of the tree.
+func (t *ByteKeyedTree) Root() *big.Int {
+	return t.tree.Root()
+}
+
+// Insert inserts a leaf with the given key and value into the tree.
+func (t *ByteKeyedTree) Insert(key []byte, value *big.Int) error {
+	binaryKey, err := t.binaryKey(key)
+	if err != nil {
+		return err
+	}
+
+	t.tree.mu.Lock()
+	defer t.tree.mu.Unlock()
+
+	return t.tree.set(binaryKey, value)
+}
+
+// Get returns the value of the leaf with the given key, if one was inserted.
+func (t *ByteKeyedTree) Get(key []byte) (*big.Int, bool) {
+	binaryKey, err := t.binaryKey(key)
+	if err != nil {
+		return nil, false
+	}
+
+	t.tree.mu.RLock()
+	defer t.tree.mu.RUnlock()
+
+	value, exists := t.tree.leaves[binaryKey]
+	return copyInt(value), exists
+}
+
+// GenerateMerklePath generates a Merkle tree path for the leaf with the given
+// key. The path is valid whether or not a leaf was inserted there; an empty
+// leaf verifies against the zero leaf.
+func (t *ByteKeyedTree) GenerateMerklePath(key []byte) ([]*MerklePathItem, error) {
+	binaryKey, err := t.binaryKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	t.tree.mu.RLock()
+	defer t.tree.mu.RUnlock()
+
+	return t.tree.generateMerklePath(t.tree.root, binaryKey), nil
+}
+
+// VerifyMerklePath verifies a Merkle tree path for the leaf with the given key
+// against the expected root hash, checking that it is the path of that key.
+func (t *ByteKeyedTree) VerifyMerklePath(key []byte, leafHash *big.Int, path []*MerklePathItem, expectedRoot *big.Int) bool {
+	binaryKey, err := t.binaryKey(key)
+	if err != nil || len(path) != len(binaryKey) {
+		return false
+	}
+	for i, item := range path {
+		isLeft := binaryKey[len(binaryKey)-1-i] == '0'
+		if item == nil || item.IsRight != isLeft {
+			return false
+		}
+	}
+	return VerifyMerklePath(leafHash, path, expectedRoot)
+}
+
+// binaryKey returns the binary path of key, checking its size or deriving it
+// for variable-length keys.
+func (t *ByteKeyedTree) binaryKey(key []byte) (string, error) {
+	if t.keySize == 0 {
+		return derivedKey(KeyDomainBytes, key, t.tree.depth), nil
+	}
+	if len(key) != t.keySize {
+		return "", fmt.Errorf("key must be %d bytes, got %d", t.keySize, len(key))
+	}
+	return getBinaryStringFromBytes(key), nil
+}
@@ -0,0 +1,146 @@
+package smt
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressTree(t *testing.T) {
+	tree := NewAddressTree()
+	assert.Equal(t, 160, tree.Tree().depth)
+
+	alice := make([]byte, AddressKeySize)
+	alice[0] = 0xa1
+	bob := make([]byte, AddressKeySize)
+	bob[19] = 0xb0
+
+	assert.NoError(t, tree.Insert(alice, big.NewInt(100)))
+	assert.NoError(t, tree.Insert(bob, big.NewInt(200)))
+	assert.Error(t, tree.Insert(alice[:19], big.NewInt(1)))
+
+	value, ok := tree.Get(alice)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(100), value)
+
+	path, err := tree.GenerateMerklePath(bob)
+	assert.NoError(t, err)
+	assert.Len(t, path, 160)
+	assert.True(t, VerifyMerklePath(big.NewInt(200), path, tree.Root()))
+
+	carol := make([]byte, AddressKeySize)
+	carol[10] = 0xc0
+	path, err = tree.GenerateMerklePath(carol)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePath(zeroLeaf, path, tree.Root()), "absent keys prove the zero leaf")
+}
+
+func TestVariableKeyTree(t *testing.T) {
+	tree := NewVariableKeyTree(zeroLeaf)
+	assert.Equal(t, 256, tree.Tree().depth)
+
+	utxo := append(make([]byte, 32), 0, 0, 0, 1)
+	assert.NoError(t, tree.Insert(utxo, big.NewInt(5)))
+	assert.NoError(t, tree.Insert([]byte("alice"), big.NewInt(7)))
+
+	value, ok := tree.Get([]byte("alice"))
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(7), value)
+	value, ok = tree.Tree().GetBig(tree.Tree().DeriveIndexBig(KeyDomainBytes, []byte("alice")))
+	assert.True(t, ok, "keys map to the indices derived in KeyDomainBytes")
+	assert.Equal(t, big.NewInt(7), value)
+
+	path, err := tree.GenerateMerklePath(utxo)
+	assert.NoError(t, err)
+	assert.True(t, tree.VerifyMerklePath(utxo, big.NewInt(5), path, tree.Root()))
+	assert.False(t, tree.VerifyMerklePath([]byte("alice"), big.NewInt(5), path, tree.Root()))
+
+	missing, err := tree.GenerateMerklePath([]byte("bob"))
+	assert.NoError(t, err)
+	assert.True(t, tree.VerifyMerklePath([]byte("bob"), zeroLeaf, missing, tree.Root()))
+}
+
+func TestCompressedTree(t *testing.T) {
+	compressed := NewSparseMerkleTree(16, zeroLeaf)
+	compressed.compressed = true
+	plain := NewSparseMerkleTree(16, zeroLeaf)
+	check := func() {
+		t.Helper()
+		assert.Equal(t, plain.Root(), compressed.Root())
+		for _, index := range []int{0, 1, 2, 0x8000, 0xffff} {
+			want, _ := plain.GenerateMerklePathAny(index)
+			got, err := compressed.GenerateMerklePathAny(index)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+		for _, path := range []string{"", "0", "1", "1010", "0000000000000001"} {
+			want, _ := plain.InspectNode(path)
+			got, err := compressed.InspectNode(path)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+		simulated := []Mutation{{Index: 4, Value: big.NewInt(4)}, {Index: 0x8004, Value: big.NewInt(8)}}
+		want, _, _ := plain.SimulateBatch(simulated)
+		got, _, err := compressed.SimulateBatch(simulated)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	var inserted []int
+	for i := 0; i < 200; i++ {
+		index := rng.Intn(1 << 16)
+		if i%10 == 0 {
+			index = index&^0xf | 1 // neighbours of earlier leaves
+		}
+		value := big.NewInt(rng.Int63())
+		assert.NoError(t, plain.Insert(index, value))
+		assert.NoError(t, compressed.Insert(index, value))
+		inserted = append(inserted, index)
+	}
+	check()
+	assert.Less(t, countNodes(compressed.root), 3*len(compressed.leaves))
+	assert.Greater(t, countNodes(plain.root), 8*len(plain.leaves))
+
+	batch := []Mutation{{Index: 1, Value: big.NewInt(1)}, {Index: 3, Value: big.NewInt(3)}, {Index: inserted[0], Value: big.NewInt(5)}}
+	_, err := plain.BatchInsert(batch)
+	assert.NoError(t, err)
+	_, err = compressed.BatchInsert(batch)
+	assert.NoError(t, err)
+	_, err = plain.ApplyParallel([]Mutation{{Index: 2, Value: big.NewInt(2)}, {Index: 0xffff, Value: big.NewInt(9)}}, 4)
+	assert.NoError(t, err)
+	_, err = compressed.ApplyParallel([]Mutation{{Index: 2, Value: big.NewInt(2)}, {Index: 0xffff, Value: big.NewInt(9)}}, 4)
+	assert.NoError(t, err)
+	check()
+
+	for _, index := range append(inserted, 1, 2, 3, 0xffff) {
+		assert.NoError(t, plain.Delete(index))
+		assert.NoError(t, compressed.Delete(index))
+	}
+	check()
+	assert.Equal(t, NewSparseMerkleTree(16, zeroLeaf).Root(), compressed.Root())
+}
+
+func TestAddressTreeCompressed(t *testing.T) {
+	tree := NewAddressTree()
+	key := make([]byte, AddressKeySize)
+	for i := 0; i < 100; i++ {
+		key[0], key[19] = byte(i), byte(i)
+		assert.NoError(t, tree.Insert(key, big.NewInt(int64(i))))
+	}
+	assert.Less(t, countNodes(tree.Tree().root), 300)
+
+	path, err := tree.GenerateMerklePath(key)
+	assert.NoError(t, err)
+	assert.True(t, tree.VerifyMerklePath(key, big.NewInt(99), path, tree.Root()))
+	key[10] = 1
+	path, err = tree.GenerateMerklePath(key)
+	assert.NoError(t, err)
+	assert.True(t, tree.VerifyMerklePath(key, zeroLeaf, path, tree.Root()))
+}
+
+func TestGetBinaryStringFromBytes(t *testing.T) {
+	assert.Equal(t, "0000000110000000", getBinaryStringFromBytes([]byte{0x01, 0x80}))
+}
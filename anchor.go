@@ -0,0 +1,103 @@
+package smt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AnchorSubmitter submits a tree head to an external append-only log, such
+// as a transparency log or a blockchain, and returns the log's receipt.
+type AnchorSubmitter interface {
+	SubmitAnchor(ctx context.Context, head TreeHead) ([]byte, error)
+}
+
+// AnchorSubmitterFunc adapts a function to an AnchorSubmitter.
+type AnchorSubmitterFunc func(ctx context.Context, head TreeHead) ([]byte, error)
+
+// SubmitAnchor implements AnchorSubmitter.
+func (f AnchorSubmitterFunc) SubmitAnchor(ctx context.Context, head TreeHead) ([]byte, error) {
+	return f(ctx, head)
+}
+
+// AnchorReceipt records that a tree head was anchored in an external log.
+type AnchorReceipt struct {
+	Head       TreeHead  // The anchored tree head.
+	Receipt    []byte    // Receipt returned by the external log.
+	AnchoredAt time.Time // Time the anchor was submitted.
+}
+
+// Anchorer periodically anchors the current root of a tree into an external
+// log and keeps the receipts.
+type Anchorer struct {
+	tree      *SparseMerkleTree
+	submitter AnchorSubmitter
+
+	mu       sync.Mutex
+	receipts []AnchorReceipt
+}
+
+// NewAnchorer creates an anchorer for tree using submitter.
+func NewAnchorer(tree *SparseMerkleTree, submitter AnchorSubmitter) *Anchorer {
+	return &Anchorer{tree: tree, submitter: submitter}
+}
+
+// AnchorNow anchors the current head of the tree and returns its receipt. If
+// the current version is already anchored, its existing receipt is returned.
+func (a *Anchorer) AnchorNow(ctx context.Context) (AnchorReceipt, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	head := a.tree.Head()
+	if n := len(a.receipts); n > 0 && a.receipts[n-1].Head.Version == head.Version {
+		return a.receipts[n-1], nil
+	}
+
+	receipt, err := a.submitter.SubmitAnchor(ctx, head)
+	if err != nil {
+		return AnchorReceipt{}, err
+	}
+
+	r := AnchorReceipt{Head: head, Receipt: receipt, AnchoredAt: time.Now()}
+	a.receipts = append(a.receipts, r)
+	return r, nil
+}
+
+// Run anchors the current head every interval until ctx is done, passing
+// submission errors to onError, which may be nil. It returns ctx.Err().
+func (a *Anchorer) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := a.AnchorNow(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Receipts returns all receipts in the order the heads were anchored.
+func (a *Anchorer) Receipts() []AnchorReceipt {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]AnchorReceipt(nil), a.receipts...)
+}
+
+// ReceiptFor returns the receipt of the given tree version, if it was anchored.
+func (a *Anchorer) ReceiptFor(version int) (AnchorReceipt, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, r := range a.receipts {
+		if r.Head.Version == version {
+			return r, true
+		}
+	}
+	return AnchorReceipt{}, false
+}
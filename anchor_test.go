@@ -0,0 +1,59 @@
+package smt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchorer(t *testing.T) {
+	tree := NewSparseMerkleTree(3, zeroLeaf)
+	var submissions int
+	anchorer := NewAnchorer(tree, AnchorSubmitterFunc(func(ctx context.Context, head TreeHead) ([]byte, error) {
+		submissions++
+		return []byte(fmt.Sprintf("entry-%d", submissions)), nil
+	}))
+
+	tree.Insert(1, big.NewInt(1))
+	receipt, err := anchorer.AnchorNow(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("entry-1"), receipt.Receipt)
+	assert.Equal(t, tree.root.Data, receipt.Head.Root)
+
+	_, err = anchorer.AnchorNow(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, submissions, "an anchored version is not submitted again")
+
+	tree.Insert(2, big.NewInt(2))
+	_, err = anchorer.AnchorNow(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, anchorer.Receipts(), 2)
+
+	r, ok := anchorer.ReceiptFor(1)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("entry-1"), r.Receipt)
+	_, ok = anchorer.ReceiptFor(5)
+	assert.False(t, ok)
+}
+
+func TestAnchorerRun(t *testing.T) {
+	tree := NewSparseMerkleTree(3, zeroLeaf)
+	var failures int32
+	anchorer := NewAnchorer(tree, AnchorSubmitterFunc(func(ctx context.Context, head TreeHead) ([]byte, error) {
+		return nil, errors.New("log unavailable")
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := anchorer.Run(ctx, 5*time.Millisecond, func(err error) { atomic.AddInt32(&failures, 1) })
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Greater(t, atomic.LoadInt32(&failures), int32(0))
+	assert.Empty(t, anchorer.Receipts())
+}
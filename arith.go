@@ -0,0 +1,92 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+)
+
+var (
+	// ErrUnderflow is returned when a subtraction would make a leaf negative.
+	ErrUnderflow = errors.New("leaf underflow")
+	// ErrOverflow is returned when an addition would leave the scalar field.
+	ErrOverflow = errors.New("leaf overflow")
+)
+
+// Add adds delta to the numeric leaf at index under the tree's lock and
+// returns the new value. An index that was never inserted holds the zero
+// leaf, as everywhere else, and the result must stay below the field order.
+// A result equal to the zero leaf removes the leaf, see setNumeric.
+func (smt *SparseMerkleTree) Add(index int, delta *big.Int) (*big.Int, error) {
+	if delta == nil {
+		return nil, fmt.Errorf("nil delta")
+	}
+	if delta.Sign() < 0 {
+		return nil, fmt.Errorf("negative delta: %s", delta)
+	}
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	value := new(big.Int).Add(smt.leafOrZero(key), delta)
+	if value.Cmp(constants.Q) >= 0 {
+		return nil, fmt.Errorf("%w at key %s: %s is not below the field order", ErrOverflow, key, value)
+	}
+
+	if err := smt.setNumeric(key, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Sub subtracts delta from the numeric leaf at index under the tree's lock and
+// returns the new value. An index that was never inserted holds the zero
+// leaf, and the result must not be negative. A result equal to the zero leaf
+// removes the leaf, see setNumeric.
+func (smt *SparseMerkleTree) Sub(index int, delta *big.Int) (*big.Int, error) {
+	if delta == nil {
+		return nil, fmt.Errorf("nil delta")
+	}
+	if delta.Sign() < 0 {
+		return nil, fmt.Errorf("negative delta: %s", delta)
+	}
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	current := smt.leafOrZero(key)
+	if current.Cmp(delta) < 0 {
+		return nil, fmt.Errorf("%w at key %s: cannot subtract %s from %s", ErrUnderflow, key, delta, current)
+	}
+
+	value := new(big.Int).Sub(current, delta)
+	if err := smt.setNumeric(key, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// setNumeric sets the leaf with the given binary key to the result of an
+// arithmetic operation as a single commit. A result equal to the zero leaf
+// removes the leaf instead, so that adding and then subtracting the same
+// delta restores the root, Has and Len. The caller must hold the write lock.
+func (smt *SparseMerkleTree) setNumeric(key string, value *big.Int) error {
+	if value.Cmp(smt.zeroLeaf) != 0 {
+		return smt.set(key, value)
+	}
+	if _, exists := smt.leaves[key]; exists {
+		smt.delete(key)
+		smt.commit()
+	}
+	return nil
+}
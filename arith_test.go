@@ -0,0 +1,86 @@
+package smt
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSub(t *testing.T) {
+	smt := NewSparseMerkleTree(3, big.NewInt(0))
+
+	value, err := smt.Add(1, big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), value)
+
+	value, err = smt.Sub(1, big.NewInt(4))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(6), value)
+
+	_, err = smt.Sub(1, big.NewInt(7))
+	assert.ErrorIs(t, err, ErrUnderflow)
+	_, err = smt.Sub(2, big.NewInt(1))
+	assert.ErrorIs(t, err, ErrUnderflow, "unset leaves hold the zero leaf 0")
+	_, err = smt.Add(1, constants.Q)
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = smt.Add(1, big.NewInt(-1))
+	assert.Error(t, err)
+
+	for _, index := range []int{8, 9, -1} {
+		_, err = smt.Add(index, big.NewInt(1))
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+		_, err = smt.Sub(index, big.NewInt(0))
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	}
+
+	assert.Equal(t, big.NewInt(6), smt.leaves[getPaddedBinaryString(1, smt.depth)])
+	assert.Len(t, smt.leaves, 1)
+}
+
+func TestAddSubZeroLeaf(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	empty := smt.Root()
+
+	value, err := smt.Add(1, big.NewInt(3))
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Add(zeroLeaf, big.NewInt(3)), value, "an unset leaf holds the zero leaf")
+
+	explicit := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, explicit.Insert(1, zeroLeaf))
+	_, err = explicit.Add(1, big.NewInt(3))
+	assert.NoError(t, err)
+	assert.Equal(t, smt.Root(), explicit.Root(), "an explicit zero leaf adds like an unset one")
+
+	value, err = smt.Sub(1, big.NewInt(3))
+	assert.NoError(t, err)
+	assert.Equal(t, zeroLeaf, value)
+	assert.Equal(t, empty, smt.Root())
+	assert.False(t, smt.IsSet(1))
+	assert.False(t, smt.Has(1))
+	assert.Equal(t, 0, smt.Len())
+
+	version := smt.Head().Version
+	_, err = smt.Add(2, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, version, smt.Head().Version, "adding 0 to an unset leaf changes nothing")
+}
+
+func TestAddConcurrent(t *testing.T) {
+	smt := NewSparseMerkleTree(2, zeroLeaf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := smt.Add(0, big.NewInt(1))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, new(big.Int).Add(zeroLeaf, big.NewInt(20)), smt.leaves[getPaddedBinaryString(0, smt.depth)])
+}
@@ -0,0 +1,110 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Mutation sets the leaf at Index to Value.
+type Mutation struct {
+	Index int      // Index of the leaf to set.
+	Value *big.Int // New value of the leaf.
+}
+
+// ApplyAtomic applies all mutations in order and returns the resulting root.
+// Every mutation is validated before any is applied, so either all listed
+// leaves change or none do.
+func (smt *SparseMerkleTree) ApplyAtomic(mutations []Mutation) (*big.Int, error) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	values := make(map[string]*big.Int, len(mutations))
+	for i, m := range mutations {
+		if err := checkIndex(m.Index, smt.depth); err != nil {
+			return nil, fmt.Errorf("mutation %d: %w", i, err)
+		}
+		if err := checkValue(m.Value); err != nil {
+			return nil, fmt.Errorf("mutation %d: %w", i, err)
+		}
+		if err := smt.checkNotTombstone(m.Value); err != nil {
+			return nil, fmt.Errorf("mutation %d: %w", i, err)
+		}
+		values[getPaddedBinaryString(m.Index, smt.depth)] = copyInt(m.Value)
+	}
+	if err := smt.applyValues(values); err != nil {
+		return nil, err
+	}
+	return copyInt(smt.root.Data), nil
+}
+
+// applyValues sets the leaves with the given binary keys to their validated
+// values as a single commit. The caller must hold the write lock.
+func (smt *SparseMerkleTree) applyValues(values map[string]*big.Int) error {
+	if err := smt.checkCapacity(values); err != nil {
+		return err
+	}
+	for key, value := range values {
+		smt.insert(key, value)
+	}
+	smt.commit()
+	return nil
+}
+
+// Swap exchanges the values of the leaves at i and j as a single commit and
+// returns the resulting root. Swapping with an index that was never inserted
+// moves the leaf there and leaves the other index unset.
+func (smt *SparseMerkleTree) Swap(i, j int) (*big.Int, error) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(i, smt.depth); err != nil {
+		return nil, err
+	}
+	if err := checkIndex(j, smt.depth); err != nil {
+		return nil, err
+	}
+
+	keyI := getPaddedBinaryString(i, smt.depth)
+	keyJ := getPaddedBinaryString(j, smt.depth)
+	valueI, valueJ := smt.leaves[keyI], smt.leaves[keyJ]
+	for _, value := range []*big.Int{valueI, valueJ} {
+		if err := smt.checkNotTombstone(value); err != nil {
+			return nil, err
+		}
+	}
+
+	// Write the leaf that shrinks the tree first, so that a swap in a full
+	// tree does not exceed its capacity halfway.
+	first, second := swapWrite{keyI, valueJ}, swapWrite{keyJ, valueI}
+	if valueI == nil || (valueJ != nil && smt.growth(keyI, valueJ) > smt.growth(keyJ, valueI)) {
+		first, second = second, first
+	}
+	for _, w := range []swapWrite{first, second} {
+		if w.value == nil {
+			if _, exists := smt.leaves[w.key]; exists {
+				smt.delete(w.key)
+			}
+			continue
+		}
+		if err := smt.put(w.key, w.value); err != nil {
+			return nil, err
+		}
+	}
+	smt.commit()
+	return copyInt(smt.root.Data), nil
+}
+
+// swapWrite is one of the two writes of a Swap. A nil value removes the leaf.
+type swapWrite struct {
+	key   string
+	value *big.Int
+}
+
+// leafOrZero returns the value of the leaf with the given key, or the zero
+// leaf if no leaf was inserted there.
+func (smt *SparseMerkleTree) leafOrZero(key string) *big.Int {
+	if value, exists := smt.leaves[key]; exists {
+		return value
+	}
+	return smt.zeroLeaf
+}
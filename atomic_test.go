@@ -0,0 +1,72 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyAtomic(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(0, big.NewInt(1))
+	before := smt.root.Data
+
+	_, err := smt.ApplyAtomic([]Mutation{{Index: 1, Value: big.NewInt(2)}, {Index: 8, Value: big.NewInt(3)}})
+	assert.Error(t, err)
+	_, err = smt.ApplyAtomic([]Mutation{{Index: 1, Value: big.NewInt(2)}, {Index: 2, Value: constants.Q}})
+	assert.Error(t, err)
+	assert.Equal(t, before, smt.root.Data, "a rejected batch must not change the tree")
+	assert.Len(t, smt.leaves, 1)
+
+	root, err := smt.ApplyAtomic([]Mutation{{Index: 1, Value: big.NewInt(2)}, {Index: 2, Value: big.NewInt(3)}})
+	assert.NoError(t, err)
+
+	expected := NewSparseMerkleTree(3, zeroLeaf)
+	expected.Insert(0, big.NewInt(1))
+	expected.Insert(1, big.NewInt(2))
+	expected.Insert(2, big.NewInt(3))
+	assert.Equal(t, expected.root.Data, root)
+}
+
+func TestSwap(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(0, big.NewInt(1))
+	smt.Insert(5, big.NewInt(2))
+
+	_, err := smt.Swap(0, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), smt.leaves[getPaddedBinaryString(0, smt.depth)])
+	assert.Equal(t, big.NewInt(1), smt.leaves[getPaddedBinaryString(5, smt.depth)])
+
+	version := smt.Head().Version
+	_, err = smt.Swap(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, version+1, smt.Head().Version, "both writes share one commit")
+	assert.False(t, smt.IsSet(0), "the unset index moves as an unset leaf")
+	assert.Equal(t, big.NewInt(2), smt.leaves[getPaddedBinaryString(3, smt.depth)])
+	assert.Equal(t, 2, smt.Len())
+
+	_, err = smt.Swap(0, 9)
+	assert.Error(t, err)
+}
+
+func TestSwapValidated(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, smt.SetTombstone(big.NewInt(0xdead)))
+	assert.NoError(t, smt.SetCapacity(CapacityConfig{MaxLeaves: 2}))
+	assert.NoError(t, smt.Insert(0, big.NewInt(1)))
+	assert.NoError(t, smt.Insert(1, big.NewInt(2)))
+
+	_, err := smt.Swap(2, 1)
+	assert.NoError(t, err, "a swap in a full tree does not grow it")
+	assert.False(t, smt.IsSet(1))
+
+	assert.NoError(t, smt.Delete(0))
+	root := smt.Root()
+	_, err = smt.Swap(0, 3)
+	assert.ErrorIs(t, err, ErrInvalidValue, "a tombstone cannot be moved")
+	assert.Equal(t, root, smt.Root())
+	assert.True(t, smt.IsDeleted(0))
+}
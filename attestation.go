@@ -0,0 +1,87 @@
+package smt
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DigestDomain separates the bundle digests of different services and
+// deployments, like an EIP-712 domain, so an attestation cannot be replayed
+// in another context.
+type DigestDomain struct {
+	Name    string `json:"name"`              // Name of the signing service.
+	Version string `json:"version"`           // Version of the signing scheme.
+	ChainID uint64 `json:"chainId,omitempty"` // Chain the roots are anchored on, if any.
+}
+
+// CanonicalBundle returns the canonical JSON serialization of bundle: proofs
+// are ordered by index, the head timestamp is in UTC and fields appear in
+// declaration order, so equal bundles always serialize to the same bytes.
+func CanonicalBundle(bundle *Bundle) ([]byte, error) {
+	if bundle == nil {
+		return nil, fmt.Errorf("nil bundle")
+	}
+
+	canonical := *bundle
+	canonical.Head.Timestamp = bundle.Head.Timestamp.UTC()
+	canonical.Inclusions = sortedBundleProofs(bundle.Inclusions)
+	canonical.Exclusions = sortedBundleProofs(bundle.Exclusions)
+	return json.Marshal(canonical)
+}
+
+// sortedBundleProofs returns a copy of proofs ordered by index.
+func sortedBundleProofs(proofs []BundleProof) []BundleProof {
+	if proofs == nil {
+		return nil
+	}
+	sorted := append([]BundleProof(nil), proofs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	return sorted
+}
+
+// BundleDigest returns the digest of bundle in domain, computed as
+// SHA-256(0x19 0x01 || SHA-256(domain JSON) || SHA-256(CanonicalBundle)),
+// following the layout of EIP-712.
+func BundleDigest(domain DigestDomain, bundle *Bundle) ([32]byte, error) {
+	encodedDomain, err := json.Marshal(domain)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	encodedBundle, err := CanonicalBundle(bundle)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	domainHash, bundleHash := sha256.Sum256(encodedDomain), sha256.Sum256(encodedBundle)
+	message := append([]byte{0x19, 0x01}, domainHash[:]...)
+	return sha256.Sum256(append(message, bundleHash[:]...)), nil
+}
+
+// AttestBundle signs the digest of bundle in domain with an operator key.
+func AttestBundle(domain DigestDomain, bundle *Bundle, key ed25519.PrivateKey) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid signing key size: %d", len(key))
+	}
+	digest, err := BundleDigest(domain, bundle)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(key, digest[:]), nil
+}
+
+// VerifyBundleAttestation checks that signature is the operator's
+// attestation of bundle in domain, and returns ErrInvalidSignature if not.
+// It does not verify the proofs of the bundle; see VerifyBundle.
+func VerifyBundleAttestation(domain DigestDomain, bundle *Bundle, operator ed25519.PublicKey, signature []byte) error {
+	digest, err := BundleDigest(domain, bundle)
+	if err != nil {
+		return err
+	}
+	if len(operator) != ed25519.PublicKeySize || !ed25519.Verify(operator, digest[:], signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package smt
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalBundle(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	assert.NoError(t, smt.Insert(9, big.NewInt(90)))
+
+	a, err := smt.NewBundle([]int{3, 9}, []int{1})
+	assert.NoError(t, err)
+	b, err := smt.NewBundle([]int{9, 3}, []int{1})
+	assert.NoError(t, err)
+	b.Head.Timestamp = a.Head.Timestamp.In(time.FixedZone("UTC+3", 3*3600))
+
+	encodedA, err := CanonicalBundle(a)
+	assert.NoError(t, err)
+	encodedB, err := CanonicalBundle(b)
+	assert.NoError(t, err)
+	assert.Equal(t, encodedA, encodedB)
+
+	// A relayed bundle decodes to the same canonical bytes.
+	relayed, err := json.Marshal(b)
+	assert.NoError(t, err)
+	var decoded Bundle
+	assert.NoError(t, json.Unmarshal(relayed, &decoded))
+	encodedDecoded, err := CanonicalBundle(&decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, encodedA, encodedDecoded)
+}
+
+func TestBundleAttestation(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	domain := DigestDomain{Name: "proof-service", Version: "1", ChainID: 1}
+
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	bundle, err := smt.NewBundle([]int{3}, nil)
+	assert.NoError(t, err)
+
+	signature, err := AttestBundle(domain, bundle, private)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyBundleAttestation(domain, bundle, public, signature))
+
+	other := domain
+	other.ChainID = 5
+	assert.ErrorIs(t, VerifyBundleAttestation(other, bundle, public, signature), ErrInvalidSignature)
+
+	bundle.Inclusions[0].Leaf = big.NewInt(31)
+	assert.ErrorIs(t, VerifyBundleAttestation(domain, bundle, public, signature), ErrInvalidSignature)
+}
@@ -0,0 +1,108 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// LeafUpdate sets the leaf at Index to Value as part of a BatchInsert.
+type LeafUpdate = Mutation
+
+// BatchInsert applies all items as a single commit and returns the resulting
+// root. Every item is validated before any is applied, so either all listed
+// leaves change or none do. Each node above the updated leaves is hashed
+// once, however many of its leaves change. If an index appears more than
+// once, the last item for it wins.
+func (smt *SparseMerkleTree) BatchInsert(items []LeafUpdate) (*big.Int, error) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	values := make(map[string]*big.Int, len(items))
+	for i, item := range items {
+		if err := checkIndex(item.Index, smt.depth); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		if err := checkValue(item.Value); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		if err := smt.checkNotTombstone(item.Value); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		values[getPaddedBinaryString(item.Index, smt.depth)] = copyInt(item.Value)
+	}
+	if err := smt.checkCapacity(values); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		smt.setLeaf(key, value)
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if len(keys) > 0 {
+		smt.root = smt.insertBatch(smt.root, keys, values, 0)
+	}
+	smt.commit()
+	return copyInt(smt.root.Data), nil
+}
+
+// NewSparseMerkleTreeFromLeaves creates a tree holding leaves, a map from
+// index to value, built bottom-up in a single commit. Each internal node is
+// hashed once and disjoint subtrees are hashed in parallel, which is much
+// faster than inserting the leaves one by one.
+func NewSparseMerkleTreeFromLeaves(depth int, zeroLeaf *big.Int, leaves map[int]*big.Int) (*SparseMerkleTree, error) {
+	smt := NewSparseMerkleTree(depth, zeroLeaf)
+	keys := make([]string, 0, len(leaves))
+	for index, value := range leaves {
+		if err := checkIndex(index, depth); err != nil {
+			return nil, err
+		}
+		if err := checkValue(value); err != nil {
+			return nil, fmt.Errorf("leaf %d: %w", index, err)
+		}
+		key := getPaddedBinaryString(index, depth)
+		smt.setLeaf(key, copyInt(value))
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if len(keys) > 0 {
+		smt.applyKeys(keys, smt.leaves, 0)
+	}
+	smt.commit()
+	return smt, nil
+}
+
+// insertBatch returns a copy of node, at the specified depth, with the leaves
+// with the given sorted keys inserted, all of which lie under node, and
+// rehashes it once.
+func (smt *SparseMerkleTree) insertBatch(node *MerkleNode, keys []string, values map[string]*big.Int, depth int) *MerkleNode {
+	if depth == smt.depth {
+		return smt.leafNode(keys[0], values[keys[0]])
+	}
+	if smt.compressed && len(keys) == 1 && (node == nil || node.leafKey == keys[0]) {
+		return smt.compressedNode(keys[0], values[keys[0]], depth, smt.hashNode)
+	}
+
+	split := sort.Search(len(keys), func(i int) bool { return getPathBit(keys[i], depth) == 1 })
+	if node.isCompressed() {
+		if getPathBit(node.leafKey, depth) == 0 {
+			node = smt.expand(node, depth, split > 0, smt.hashNode)
+		} else {
+			node = smt.expand(node, depth, split < len(keys), smt.hashNode)
+		}
+	} else {
+		node = copyNode(node)
+	}
+	if split > 0 {
+		node.Left = smt.insertBatch(node.Left, keys[:split], values, depth+1)
+	}
+	if split < len(keys) {
+		node.Right = smt.insertBatch(node.Right, keys[split:], values, depth+1)
+	}
+	node.Data = smt.hashNode(node.Left, node.Right, smt.depth-depth)
+	return node
+}
@@ -0,0 +1,74 @@
+package smt
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchInsert(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	batched, sequential := NewSparseMerkleTree(10, zeroLeaf), NewSparseMerkleTree(10, zeroLeaf)
+	batched.Insert(5, big.NewInt(1))
+	sequential.Insert(5, big.NewInt(1))
+
+	items := make([]LeafUpdate, 500)
+	for i := range items {
+		items[i] = LeafUpdate{Index: rng.Intn(1 << 10), Value: big.NewInt(rng.Int63())}
+		sequential.Insert(items[i].Index, items[i].Value)
+	}
+
+	version := batched.Head().Version
+	root, err := batched.BatchInsert(items)
+	assert.NoError(t, err)
+	assert.Equal(t, sequential.root.Data, root)
+	assert.Equal(t, sequential.leaves, batched.leaves)
+	assert.Equal(t, version+1, batched.Head().Version)
+
+	path, err := batched.GenerateMerklePath(items[0].Index)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePath(batched.leaves[getPaddedBinaryString(items[0].Index, 10)], path, root))
+}
+
+func TestBatchInsertAtomic(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	smt.Insert(1, big.NewInt(1))
+	root := smt.root.Data
+
+	_, err := smt.BatchInsert([]LeafUpdate{{Index: 2, Value: big.NewInt(2)}, {Index: 16, Value: big.NewInt(3)}})
+	assert.Error(t, err)
+	_, err = smt.BatchInsert([]LeafUpdate{{Index: 2, Value: big.NewInt(2)}, {Index: 3}})
+	assert.Error(t, err)
+
+	assert.Equal(t, root, smt.root.Data)
+	assert.Len(t, smt.leaves, 1)
+}
+
+func TestNewSparseMerkleTreeFromLeaves(t *testing.T) {
+	leaves := make(map[int]*big.Int)
+	incremental := NewSparseMerkleTree(10, zeroLeaf)
+	for i := 0; i < 300; i++ {
+		index := (i * 37) % 1024
+		leaves[index] = big.NewInt(int64(i + 1))
+		assert.NoError(t, incremental.Insert(index, big.NewInt(int64(i+1))))
+	}
+
+	smt, err := NewSparseMerkleTreeFromLeaves(10, zeroLeaf, leaves)
+	assert.NoError(t, err)
+	assert.Equal(t, incremental.Root(), smt.Root())
+	assert.Equal(t, 300, smt.Len())
+	assert.Equal(t, 1, smt.Head().Version)
+
+	path, err := smt.GenerateMerklePath(37)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePath(big.NewInt(2), path, smt.Root()))
+
+	empty, err := NewSparseMerkleTreeFromLeaves(10, zeroLeaf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, NewSparseMerkleTree(10, zeroLeaf).Root(), empty.Root())
+
+	_, err = NewSparseMerkleTreeFromLeaves(4, zeroLeaf, map[int]*big.Int{16: big.NewInt(1)})
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
@@ -0,0 +1,101 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInvalidProof is returned when a proof does not verify.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// ProofWithLeaf is a Merkle path for the leaf at Index holding Leaf, as
+// verified by VerifyMerklePaths.
+type ProofWithLeaf = MembershipClaim
+
+// BatchVerifyError reports which proofs of a batch failed to verify. It
+// wraps ErrInvalidProof.
+type BatchVerifyError struct {
+	Failed []int // Positions in the batch of the proofs that failed, in order.
+	Total  int   // Number of proofs in the batch.
+}
+
+// Error implements error.
+func (e *BatchVerifyError) Error() string {
+	return fmt.Sprintf("%d of %d proofs failed to verify: %v", len(e.Failed), e.Total, e.Failed)
+}
+
+// Unwrap returns ErrInvalidProof.
+func (e *BatchVerifyError) Unwrap() error {
+	return ErrInvalidProof
+}
+
+// nodePosition identifies a node of a tree of a given depth by its height and
+// its position among the nodes of that height.
+type nodePosition struct {
+	depth, height, position int
+}
+
+// VerifyMerklePaths verifies that every proof leads from its leaf to root and
+// is the path of its index. Nodes already authenticated by an earlier proof
+// are not hashed again, so proofs of nearby leaves share the work above the
+// point where their paths meet. It returns a *BatchVerifyError listing every
+// proof that failed.
+func VerifyMerklePaths(proofs []ProofWithLeaf, root *big.Int) error {
+	verified := make(map[nodePosition]*big.Int)
+	var failed []int
+	for i := range proofs {
+		if !verifyShared(&proofs[i], root, verified) {
+			failed = append(failed, i)
+		}
+	}
+	if len(failed) > 0 {
+		return &BatchVerifyError{Failed: failed, Total: len(proofs)}
+	}
+	return nil
+}
+
+// verifyShared verifies proof against root, stopping at the first node found
+// in verified, and records the nodes it authenticates there.
+func verifyShared(proof *ProofWithLeaf, root *big.Int, verified map[nodePosition]*big.Int) bool {
+	if proof.Leaf == nil || root == nil || !pathMatchesIndex(proof.Path, proof.Index) {
+		return false
+	}
+
+	depth := len(proof.Path)
+	nodes := make([]*big.Int, 0, depth)
+	current := proof.Leaf
+	for height, item := range proof.Path {
+		if known, ok := verified[nodePosition{depth, height, proof.Index >> height}]; ok {
+			if known.Cmp(current) != 0 {
+				return false
+			}
+			break
+		}
+		if item.SiblingHash == nil {
+			return false
+		}
+
+		nodes = append(nodes, current)
+		var err error
+		if item.IsRight {
+			current, err = PoseidonHasher.Hash(current, item.SiblingHash)
+		} else {
+			current, err = PoseidonHasher.Hash(item.SiblingHash, current)
+		}
+		if err != nil {
+			return false
+		}
+		if height == depth-1 && current.Cmp(root) != 0 {
+			return false
+		}
+	}
+	if depth == 0 && current.Cmp(root) != 0 {
+		return false
+	}
+
+	for height, node := range nodes {
+		verified[nodePosition{depth, height, proof.Index >> height}] = node
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package smt
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyMerklePaths(t *testing.T) {
+	smt := NewDeterministicSparseMerkleTree(4, zeroLeaf)
+	var proofs []ProofWithLeaf
+	for index := 0; index < 16; index += 3 {
+		claim, err := smt.MembershipClaim(index)
+		assert.NoError(t, err)
+		proofs = append(proofs, *claim)
+	}
+	assert.NoError(t, VerifyMerklePaths(proofs, smt.Root()))
+	assert.NoError(t, VerifyMerklePaths(nil, smt.Root()))
+
+	proofs[1].Leaf = big.NewInt(99)
+	proofs[4].Index = 13
+	err := VerifyMerklePaths(proofs, smt.Root())
+	assert.ErrorIs(t, err, ErrInvalidProof)
+	var batchErr *BatchVerifyError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, []int{1, 4}, batchErr.Failed)
+	assert.Equal(t, len(proofs), batchErr.Total)
+
+	err = VerifyMerklePaths(proofs[:1], zeroLeaf)
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, []int{0}, batchErr.Failed)
+}
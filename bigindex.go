@@ -0,0 +1,101 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// InsertBig inserts a leaf at an index of arbitrary width, for trees too deep
+// for their indices to fit in an int, such as address-keyed trees.
+func (smt *SparseMerkleTree) InsertBig(index, value *big.Int) error {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	key, err := smt.bigKey(index)
+	if err != nil {
+		return err
+	}
+	return smt.set(key, value)
+}
+
+// GetBig returns the value of the leaf at an index of arbitrary width, if one
+// was inserted.
+func (smt *SparseMerkleTree) GetBig(index *big.Int) (*big.Int, bool) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	key, err := smt.bigKey(index)
+	if err != nil {
+		return nil, false
+	}
+	value, exists := smt.leaves[key]
+	return copyInt(value), exists
+}
+
+// GenerateMerklePathBig generates a Merkle tree path for the leaf at an index
+// of arbitrary width.
+func (smt *SparseMerkleTree) GenerateMerklePathBig(index *big.Int) ([]*MerklePathItem, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	key, err := smt.bigKey(index)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := smt.leaves[key]; !exists {
+		return nil, fmt.Errorf("no leaf exists at key: %s", key)
+	}
+	return smt.generateMerklePath(smt.root, key), nil
+}
+
+// GenerateExclusionPathBig generates a Merkle tree path proving that no leaf
+// was inserted at an index of arbitrary width. The path verifies against the
+// zero leaf.
+func (smt *SparseMerkleTree) GenerateExclusionPathBig(index *big.Int) ([]*MerklePathItem, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	key, err := smt.bigKey(index)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := smt.leaves[key]; exists {
+		return nil, fmt.Errorf("leaf exists at key: %s", key)
+	}
+	return smt.generateMerklePath(smt.root, key), nil
+}
+
+// VerifyMerklePathBig verifies a Merkle tree path against the expected root
+// hash and checks that it is the path of the leaf at index.
+func VerifyMerklePathBig(index, leafHash *big.Int, path []*MerklePathItem, expectedRoot *big.Int) bool {
+	if index == nil || index.Sign() < 0 || index.BitLen() > len(path) {
+		return false
+	}
+	for i, item := range path {
+		if item == nil || item.IsRight != (index.Bit(i) == 0) {
+			return false
+		}
+	}
+	return VerifyMerklePath(leafHash, path, expectedRoot)
+}
+
+// bigKey returns the binary key of the leaf at an index of arbitrary width.
+func (smt *SparseMerkleTree) bigKey(index *big.Int) (string, error) {
+	return getBigPaddedBinaryString(index, smt.depth)
+}
+
+// getBigPaddedBinaryString returns the binary key of the leaf at an index of
+// arbitrary width in a tree of the given depth.
+func getBigPaddedBinaryString(index *big.Int, depth int) (string, error) {
+	if index == nil || index.Sign() < 0 || index.BitLen() > depth {
+		return "", fmt.Errorf("%w: index %v for depth %d", ErrIndexOutOfRange, index, depth)
+	}
+	return fmt.Sprintf("%0*b", depth, index), nil
+}
+
+// getBigIndexFromBinaryString converts a binary key of any width back to its
+// leaf index.
+func getBigIndexFromBinaryString(key string) *big.Int {
+	index, _ := new(big.Int).SetString(key, 2)
+	return index
+}
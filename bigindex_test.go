@@ -0,0 +1,65 @@
+package smt
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertBig(t *testing.T) {
+	smt := NewSparseMerkleTree(160, zeroLeaf)
+	index, _ := new(big.Int).SetString("ffffffffffffffffffffffffffffffffffff0001", 16)
+
+	assert.NoError(t, smt.InsertBig(index, big.NewInt(9)))
+	value, ok := smt.GetBig(index)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(9), value)
+
+	path, err := smt.GenerateMerklePathBig(index)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePathBig(index, big.NewInt(9), path, smt.root.Data))
+	assert.False(t, VerifyMerklePathBig(new(big.Int).Add(index, big.NewInt(1)), big.NewInt(9), path, smt.root.Data))
+
+	other := big.NewInt(3)
+	exclusion, err := smt.GenerateExclusionPathBig(other)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePathBig(other, zeroLeaf, exclusion, smt.root.Data))
+	_, err = smt.GenerateExclusionPathBig(index)
+	assert.Error(t, err)
+
+	tooWide := new(big.Int).Lsh(big.NewInt(1), 160)
+	assert.ErrorIs(t, smt.InsertBig(tooWide, big.NewInt(1)), ErrIndexOutOfRange)
+	assert.ErrorIs(t, smt.InsertBig(big.NewInt(-1), big.NewInt(1)), ErrIndexOutOfRange)
+}
+
+func TestInsertBigMatchesInsert(t *testing.T) {
+	a, b := NewSparseMerkleTree(8, zeroLeaf), NewSparseMerkleTree(8, zeroLeaf)
+	assert.NoError(t, a.Insert(200, big.NewInt(1)))
+	assert.NoError(t, b.InsertBig(big.NewInt(200), big.NewInt(1)))
+	assert.Equal(t, a.root.Data, b.root.Data)
+	assert.Equal(t, a.leaves, b.leaves)
+}
+
+func TestDepth256(t *testing.T) {
+	smt := NewSparseMerkleTree(256, zeroLeaf)
+	indices := make([]*big.Int, 8)
+	for i := range indices {
+		digest := sha256.Sum256([]byte{byte(i)})
+		indices[i] = new(big.Int).SetBytes(digest[:])
+		assert.NoError(t, smt.InsertBig(indices[i], big.NewInt(int64(i+1))))
+	}
+
+	for i, index := range indices {
+		path, err := smt.GenerateMerklePathBig(index)
+		assert.NoError(t, err)
+		assert.Len(t, path, 256)
+		assert.True(t, VerifyMerklePathBig(index, big.NewInt(int64(i+1)), path, smt.Root()))
+	}
+
+	absent := new(big.Int).Lsh(big.NewInt(1), 255)
+	exclusion, err := smt.GenerateExclusionPathBig(absent)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePathBig(absent, zeroLeaf, exclusion, smt.Root()))
+}
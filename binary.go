@@ -0,0 +1,110 @@
+package smt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// fieldSize is the size of an encoded field element.
+const fieldSize = 32
+
+// The binary encodings of proofs are canonical: the index as 8 big-endian
+// bytes, then each field element of the proof as 32 big-endian bytes, then
+// the path in the format of BinaryCodec, from the leaf up.
+
+// MarshalBinary encodes the claim as its index, leaf and path.
+func (c *MembershipClaim) MarshalBinary() ([]byte, error) {
+	return marshalProof(c.Index, []*big.Int{c.Leaf}, c.Path)
+}
+
+// UnmarshalBinary decodes a claim encoded by MarshalBinary.
+func (c *MembershipClaim) UnmarshalBinary(data []byte) error {
+	index, fields, path, err := unmarshalProof(data, 1)
+	if err != nil {
+		return err
+	}
+	*c = MembershipClaim{Index: index, Leaf: fields[0], Path: path}
+	return nil
+}
+
+// MarshalBinary encodes the claim as its index and path.
+func (c *NonMembershipClaim) MarshalBinary() ([]byte, error) {
+	return marshalProof(c.Index, nil, c.Path)
+}
+
+// UnmarshalBinary decodes a claim encoded by MarshalBinary.
+func (c *NonMembershipClaim) UnmarshalBinary(data []byte) error {
+	index, _, path, err := unmarshalProof(data, 0)
+	if err != nil {
+		return err
+	}
+	*c = NonMembershipClaim{Index: index, Path: path}
+	return nil
+}
+
+// MarshalBinary encodes the proof as its index, old and new leaf, old and new
+// root, and path.
+func (p *TransitionProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(p.Index, []*big.Int{p.OldLeaf, p.NewLeaf, p.OldRoot, p.NewRoot}, p.Path)
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (p *TransitionProof) UnmarshalBinary(data []byte) error {
+	index, fields, path, err := unmarshalProof(data, 4)
+	if err != nil {
+		return err
+	}
+	*p = TransitionProof{Index: index, OldLeaf: fields[0], NewLeaf: fields[1], OldRoot: fields[2], NewRoot: fields[3], Path: path}
+	return nil
+}
+
+// marshalProof encodes index, fields and path in the canonical proof format.
+func marshalProof(index int, fields []*big.Int, path []*MerklePathItem) ([]byte, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("%w: index %d", ErrIndexOutOfRange, index)
+	}
+	encodedPath, err := BinaryCodec{}.EncodeProof(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 8, 8+len(fields)*fieldSize+len(encodedPath))
+	binary.BigEndian.PutUint64(data, uint64(index))
+	for i, field := range fields {
+		if field == nil || !utils.CheckBigIntInField(field) {
+			return nil, fmt.Errorf("proof field %d is not a field element", i)
+		}
+		data = append(data, field.FillBytes(make([]byte, fieldSize))...)
+	}
+	return append(data, encodedPath...), nil
+}
+
+// unmarshalProof decodes a proof with the given number of fields encoded by
+// marshalProof.
+func unmarshalProof(data []byte, fieldCount int) (int, []*big.Int, []*MerklePathItem, error) {
+	header := 8 + fieldCount*fieldSize
+	if len(data) < header {
+		return 0, nil, nil, fmt.Errorf("binary proof too short: %d bytes", len(data))
+	}
+	index := binary.BigEndian.Uint64(data)
+	if index > math.MaxInt64 {
+		return 0, nil, nil, fmt.Errorf("%w: index %d", ErrIndexOutOfRange, index)
+	}
+
+	fields := make([]*big.Int, fieldCount)
+	for i := range fields {
+		fields[i] = new(big.Int).SetBytes(data[8+i*fieldSize : 8+(i+1)*fieldSize])
+		if !utils.CheckBigIntInField(fields[i]) {
+			return 0, nil, nil, fmt.Errorf("proof field %d is not a field element", i)
+		}
+	}
+	path, err := BinaryCodec{}.DecodeProof(data[header:])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return int(index), fields, path, nil
+}
@@ -0,0 +1,64 @@
+package smt
+
+import (
+	"encoding"
+	"math/big"
+	"testing"
+
+	"github.com/pycckuu/smt/verify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofBinary(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	assert.NoError(t, smt.Insert(200, big.NewInt(2)))
+
+	member, err := smt.MembershipClaim(3)
+	assert.NoError(t, err)
+	nonMember, err := smt.NonMembershipClaim(4)
+	assert.NoError(t, err)
+	_, transitions, err := smt.SimulateBatch([]Mutation{{Index: 7, Value: big.NewInt(70)}})
+	assert.NoError(t, err)
+	transition := &transitions[0]
+
+	for _, c := range []struct {
+		proof   encoding.BinaryMarshaler
+		decoded encoding.BinaryUnmarshaler
+		fields  int
+	}{
+		{member, &MembershipClaim{}, 1},
+		{nonMember, &NonMembershipClaim{}, 0},
+		{transition, &TransitionProof{}, 4},
+	} {
+		data, err := c.proof.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Len(t, data, 8+c.fields*32+8*33)
+		assert.NoError(t, c.decoded.UnmarshalBinary(data))
+		assert.Equal(t, c.proof, c.decoded)
+
+		again, err := c.decoded.(encoding.BinaryMarshaler).MarshalBinary()
+		assert.NoError(t, err)
+		assert.Equal(t, data, again, "the encoding is canonical")
+
+		assert.Error(t, c.decoded.UnmarshalBinary(data[:len(data)-1]))
+		assert.Error(t, c.decoded.UnmarshalBinary(data[:7]))
+	}
+	assert.NoError(t, Verify(member, smt.Root(), zeroLeaf))
+}
+
+func TestPathItemBinary(t *testing.T) {
+	item := &MerklePathItem{SiblingHash: big.NewInt(42), IsRight: true}
+	data, err := item.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Len(t, data, verify.BinaryItemSize)
+
+	var decoded MerklePathItem
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, *item, decoded)
+
+	data[0] = 2
+	assert.Error(t, decoded.UnmarshalBinary(data))
+	_, err = (&MerklePathItem{}).MarshalBinary()
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// FlagsPerLeaf is the number of flags packed into each leaf of a BitmapTree,
+// 31 bytes so that every word is a field element.
+const FlagsPerLeaf = 248
+
+// BitmapTree is a sparse Merkle tree whose leaves are words of FlagsPerLeaf
+// boolean flags, for allow/deny registries that would otherwise spend a
+// whole leaf on every bit. Flag f is bit f%FlagsPerLeaf of the leaf at index
+// f/FlagsPerLeaf. Its zero leaf is 0, so every flag starts cleared.
+type BitmapTree struct {
+	tree *SparseMerkleTree
+}
+
+// FlagProof opens a single flag: it proves the word holding the flag and
+// reports the flag's bit in it.
+type FlagProof struct {
+	Flag int               `json:"flag"` // Position of the flag.
+	Set  bool              `json:"set"`  // Whether the flag is set.
+	Word *big.Int          `json:"word"` // Leaf holding the flag.
+	Path []*MerklePathItem `json:"path"` // Merkle path of the word.
+}
+
+// NewBitmapTree creates a tree of 2^depth words, holding
+// FlagsPerLeaf*2^depth cleared flags.
+func NewBitmapTree(depth int) *BitmapTree {
+	return &BitmapTree{tree: NewSparseMerkleTree(depth, big.NewInt(0))}
+}
+
+// Tree returns the underlying sparse Merkle tree.
+func (b *BitmapTree) Tree() *SparseMerkleTree {
+	return b.tree
+}
+
+// Root returns the root hash of the tree.
+func (b *BitmapTree) Root() *big.Int {
+	return b.tree.Root()
+}
+
+// IsSet reports whether flag is set.
+func (b *BitmapTree) IsSet(flag int) (bool, error) {
+	index, bit, err := b.position(flag)
+	if err != nil {
+		return false, err
+	}
+	word, err := b.tree.Leaf(index)
+	if err != nil {
+		return false, err
+	}
+	return word.Bit(bit) == 1, nil
+}
+
+// Set sets flag.
+func (b *BitmapTree) Set(flag int) error {
+	return b.setBit(flag, 1)
+}
+
+// Clear clears flag.
+func (b *BitmapTree) Clear(flag int) error {
+	return b.setBit(flag, 0)
+}
+
+// setBit sets the bit of flag to value, committing only if it changes.
+func (b *BitmapTree) setBit(flag int, value uint) error {
+	index, bit, err := b.position(flag)
+	if err != nil {
+		return err
+	}
+
+	b.tree.mu.Lock()
+	defer b.tree.mu.Unlock()
+
+	key := getPaddedBinaryString(index, b.tree.depth)
+	word := b.tree.leafOrZero(key)
+	if word.Bit(bit) == value {
+		return nil
+	}
+	return b.tree.set(key, new(big.Int).SetBit(word, bit, value))
+}
+
+// Prove returns a proof opening flag.
+func (b *BitmapTree) Prove(flag int) (*FlagProof, error) {
+	index, bit, err := b.position(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	b.tree.mu.RLock()
+	defer b.tree.mu.RUnlock()
+
+	key := getPaddedBinaryString(index, b.tree.depth)
+	word := b.tree.leafOrZero(key)
+	return &FlagProof{Flag: flag, Set: word.Bit(bit) == 1, Word: word, Path: b.tree.generateMerklePath(b.tree.root, key)}, nil
+}
+
+// position returns the leaf index and bit of flag.
+func (b *BitmapTree) position(flag int) (int, int, error) {
+	if flag < 0 {
+		return 0, 0, fmt.Errorf("%w: flag %d", ErrIndexOutOfRange, flag)
+	}
+	index := flag / FlagsPerLeaf
+	if err := checkIndex(index, b.tree.depth); err != nil {
+		return 0, 0, fmt.Errorf("flag %d: %w", flag, err)
+	}
+	return index, flag % FlagsPerLeaf, nil
+}
+
+// VerifyFlagProof checks that proof opens its flag correctly in the bitmap
+// tree with the given root.
+func VerifyFlagProof(proof *FlagProof, root *big.Int) bool {
+	if proof == nil || proof.Word == nil || root == nil || proof.Flag < 0 || proof.Word.BitLen() > FlagsPerLeaf {
+		return false
+	}
+	if proof.Set != (proof.Word.Bit(proof.Flag%FlagsPerLeaf) == 1) {
+		return false
+	}
+	return pathMatchesIndex(proof.Path, proof.Flag/FlagsPerLeaf) && VerifyMerklePath(proof.Word, proof.Path, root)
+}
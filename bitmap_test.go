@@ -0,0 +1,61 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitmapTree(t *testing.T) {
+	flags := NewBitmapTree(4)
+
+	assert.NoError(t, flags.Set(3))
+	assert.NoError(t, flags.Set(FlagsPerLeaf+1))
+	assert.NoError(t, flags.Set(FlagsPerLeaf-1))
+
+	set, err := flags.IsSet(3)
+	assert.NoError(t, err)
+	assert.True(t, set)
+	set, err = flags.IsSet(4)
+	assert.NoError(t, err)
+	assert.False(t, set)
+
+	word, _ := flags.Tree().Get(0)
+	assert.Equal(t, new(big.Int).SetBit(big.NewInt(8), FlagsPerLeaf-1, 1), word)
+	assert.NoError(t, checkValue(word))
+
+	version := flags.Tree().Head().Version
+	assert.NoError(t, flags.Set(3))
+	assert.Equal(t, version, flags.Tree().Head().Version, "setting a set flag does not commit")
+
+	assert.NoError(t, flags.Clear(3))
+	set, _ = flags.IsSet(3)
+	assert.False(t, set)
+
+	assert.ErrorIs(t, flags.Set(16*FlagsPerLeaf), ErrIndexOutOfRange)
+	assert.ErrorIs(t, flags.Set(-1), ErrIndexOutOfRange)
+}
+
+func TestFlagProof(t *testing.T) {
+	flags := NewBitmapTree(4)
+	assert.NoError(t, flags.Set(FlagsPerLeaf+7))
+
+	proof, err := flags.Prove(FlagsPerLeaf + 7)
+	assert.NoError(t, err)
+	assert.True(t, proof.Set)
+	assert.True(t, VerifyFlagProof(proof, flags.Root()))
+
+	cleared, err := flags.Prove(FlagsPerLeaf + 8)
+	assert.NoError(t, err)
+	assert.False(t, cleared.Set)
+	assert.True(t, VerifyFlagProof(cleared, flags.Root()))
+
+	forged := *cleared
+	forged.Set = true
+	assert.False(t, VerifyFlagProof(&forged, flags.Root()))
+
+	moved := *proof
+	moved.Flag = 7
+	assert.False(t, VerifyFlagProof(&moved, flags.Root()))
+}
@@ -0,0 +1,197 @@
+package smt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// TreeHead identifies a published state of a sparse Merkle tree.
+type TreeHead struct {
+	Depth     int       `json:"depth"`     // The depth of the tree.
+	ZeroLeaf  *big.Int  `json:"zeroLeaf"`  // Hash of the zero leaf.
+	Root      *big.Int  `json:"root"`      // Root hash of the tree.
+	Version   int       `json:"version"`   // Number of commits that produced this state.
+	Timestamp time.Time `json:"timestamp"` // Time of the commit that produced this state.
+	Hasher    string    `json:"hasher"`    // Fingerprint of the node hasher, see Hasher.Fingerprint.
+}
+
+// BundleProof is a Merkle path for a single index carried in a Bundle.
+type BundleProof struct {
+	Index int               `json:"index"`          // Index of the proven leaf.
+	Key   string            `json:"key,omitempty"`  // Application key of the leaf, if known.
+	Leaf  *big.Int          `json:"leaf,omitempty"` // Value of the leaf, omitted for exclusion proofs.
+	Path  []*MerklePathItem `json:"path"`           // Merkle path from the leaf to the root.
+}
+
+// HistoryProof proves that a tree head root was recorded in a RootHistory.
+type HistoryProof struct {
+	Version  int               `json:"version"`  // Version at which the root was recorded.
+	MetaRoot *big.Int          `json:"metaRoot"` // Meta-root of the history.
+	Path     []*MerklePathItem `json:"path"`     // Merkle path from the root to the meta-root.
+}
+
+// Bundle packages a tree head and the operator's signature of it with
+// inclusion and exclusion proofs against the head and an optional proof that
+// the head is part of a root history.
+type Bundle struct {
+	Head          TreeHead      `json:"head"`
+	HeadSignature []byte        `json:"headSignature,omitempty"` // Ed25519 signature of the head, see SignHead.
+	Inclusions    []BundleProof `json:"inclusions,omitempty"`
+	Exclusions    []BundleProof `json:"exclusions,omitempty"`
+	History       *HistoryProof `json:"history,omitempty"`
+}
+
+// Claims are the statements established by a verified bundle.
+type Claims struct {
+	Head       TreeHead         // The tree head the proofs were verified against.
+	Members    map[int]*big.Int // Leaf values proven to be in the tree, by index.
+	NonMembers []int            // Indices proven to hold the zero leaf.
+	Version    int              // Version of the head in the root history, or -1 without a history proof.
+	MetaRoot   *big.Int         // Meta-root the history proof was verified against, or nil.
+}
+
+// NewBundle creates a bundle proving the leaves at members are present and the
+// leaves at nonMembers are empty in the current state of the tree.
+func (smt *SparseMerkleTree) NewBundle(members, nonMembers []int) (*Bundle, error) {
+	bundle, _, err := smt.NewBundleContext(context.Background(), members, nonMembers, nil)
+	return bundle, err
+}
+
+// AttachHistory adds a proof that the bundle's head root was recorded in
+// history at the given version.
+func (b *Bundle) AttachHistory(history *RootHistory, version int) error {
+	root, err := history.RootAt(version)
+	if err != nil {
+		return err
+	}
+	if root.Cmp(b.Head.Root) != 0 {
+		return fmt.Errorf("root recorded for version %d does not match the bundle head", version)
+	}
+
+	path, err := history.ProveRoot(version)
+	if err != nil {
+		return err
+	}
+	b.History = &HistoryProof{Version: version, MetaRoot: history.MetaRoot(), Path: path}
+	return nil
+}
+
+// SignHead signs the head of the bundle with an operator key, see
+// SignTreeHead.
+func (b *Bundle) SignHead(key ed25519.PrivateKey) error {
+	signature, err := SignTreeHead(b.Head, key)
+	if err != nil {
+		return err
+	}
+	b.HeadSignature = signature
+	return nil
+}
+
+// SignTreeHead signs the JSON encoding of head, with its timestamp in UTC,
+// with an operator key.
+func SignTreeHead(head TreeHead, key ed25519.PrivateKey) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid signing key size: %d", len(key))
+	}
+	message, err := signedHead(head)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(key, message), nil
+}
+
+// VerifyTreeHead checks that signature is the operator's signature of head,
+// and returns ErrInvalidSignature if not.
+func VerifyTreeHead(head TreeHead, operator ed25519.PublicKey, signature []byte) error {
+	message, err := signedHead(head)
+	if err != nil {
+		return err
+	}
+	if len(operator) != ed25519.PublicKeySize || !ed25519.Verify(operator, message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signedHead returns the message signed for head.
+func signedHead(head TreeHead) ([]byte, error) {
+	head.Timestamp = head.Timestamp.UTC()
+	return json.Marshal(head)
+}
+
+// VerifyBundle parses a JSON encoded bundle, checks that its head is signed
+// by operator and has the trusted zeroLeaf, and verifies every proof in it,
+// returning the claims it establishes. Exclusion proofs are checked against
+// zeroLeaf rather than the zero leaf of the head. The claims are only as
+// trustworthy as the operator key and, with a history proof, the meta-root
+// they were verified against, which the caller must compare with a trusted
+// value.
+func VerifyBundle(bundle []byte, zeroLeaf *big.Int, operator ed25519.PublicKey) (Claims, error) {
+	var b Bundle
+	if err := json.Unmarshal(bundle, &b); err != nil {
+		return Claims{}, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if err := VerifyTreeHead(b.Head, operator, b.HeadSignature); err != nil {
+		return Claims{}, fmt.Errorf("bundle head: %w", err)
+	}
+	return verifyBundle(&b, zeroLeaf)
+}
+
+// verifyBundle verifies the proofs of b against its head, checking exclusion
+// proofs against zeroLeaf. The head must already be trusted.
+func verifyBundle(b *Bundle, zeroLeaf *big.Int) (Claims, error) {
+	if b.Head.Root == nil {
+		return Claims{}, fmt.Errorf("invalid bundle: incomplete tree head")
+	}
+	if zeroLeaf == nil || b.Head.ZeroLeaf == nil || b.Head.ZeroLeaf.Cmp(zeroLeaf) != 0 {
+		return Claims{}, fmt.Errorf("bundle head does not have the trusted zero leaf")
+	}
+
+	claims := Claims{Head: b.Head, Members: make(map[int]*big.Int), Version: -1}
+
+	for _, proof := range b.Inclusions {
+		if proof.Leaf == nil {
+			return Claims{}, fmt.Errorf("inclusion proof for index %d has no leaf", proof.Index)
+		}
+		if !verifyBundleProof(b.Head, proof.Index, proof.Leaf, proof.Path) {
+			return Claims{}, fmt.Errorf("invalid inclusion proof for index %d", proof.Index)
+		}
+		claims.Members[proof.Index] = proof.Leaf
+	}
+
+	for _, proof := range b.Exclusions {
+		if !verifyBundleProof(b.Head, proof.Index, zeroLeaf, proof.Path) {
+			return Claims{}, fmt.Errorf("invalid exclusion proof for index %d", proof.Index)
+		}
+		claims.NonMembers = append(claims.NonMembers, proof.Index)
+	}
+
+	if b.History != nil {
+		if b.History.MetaRoot == nil || !VerifyRootAtVersion(b.Head.Root, b.History.Version, b.History.Path, b.History.MetaRoot) {
+			return Claims{}, fmt.Errorf("invalid history proof for version %d", b.History.Version)
+		}
+		claims.Version = b.History.Version
+		claims.MetaRoot = b.History.MetaRoot
+	}
+
+	return claims, nil
+}
+
+// verifyBundleProof verifies a Merkle path for the leaf at index against head.
+func verifyBundleProof(head TreeHead, index int, leaf *big.Int, path []*MerklePathItem) bool {
+	if len(path) != head.Depth || !pathMatchesIndex(path, index) || !utils.CheckBigIntInField(leaf) {
+		return false
+	}
+	for _, item := range path {
+		if item.SiblingHash == nil || !utils.CheckBigIntInField(item.SiblingHash) {
+			return false
+		}
+	}
+	return VerifyMerklePath(leaf, path, head.Root)
+}
@@ -0,0 +1,99 @@
+package smt
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyBundle(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	tree := NewSparseMerkleTree(4, zeroLeaf)
+	tree.Insert(1, big.NewInt(10))
+	tree.Insert(9, big.NewInt(90))
+
+	history := NewRootHistory(2, zeroLeaf)
+	version, err := history.Append(tree.root.Data)
+	assert.NoError(t, err)
+
+	bundle, err := tree.NewBundle([]int{1, 9}, []int{0, 15})
+	assert.NoError(t, err)
+	assert.NoError(t, bundle.AttachHistory(history, version))
+	assert.NoError(t, bundle.SignHead(private))
+
+	encoded, err := json.Marshal(bundle)
+	assert.NoError(t, err)
+
+	claims, err := VerifyBundle(encoded, zeroLeaf, public)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tree.root.Data.Cmp(claims.Head.Root))
+	assert.Equal(t, 0, big.NewInt(10).Cmp(claims.Members[1]))
+	assert.Equal(t, 0, big.NewInt(90).Cmp(claims.Members[9]))
+	assert.Equal(t, []int{0, 15}, claims.NonMembers)
+	assert.Equal(t, version, claims.Version)
+	assert.Equal(t, 0, history.MetaRoot().Cmp(claims.MetaRoot))
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	_, err = VerifyBundle(encoded, zeroLeaf, other)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = VerifyBundle(encoded, big.NewInt(1), public)
+	assert.Error(t, err)
+
+	bundle.HeadSignature = nil
+	encoded, _ = json.Marshal(bundle)
+	_, err = VerifyBundle(encoded, zeroLeaf, public)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyBundleRejectsTampering(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	tree := NewSparseMerkleTree(3, zeroLeaf)
+	tree.Insert(2, big.NewInt(7))
+
+	_, err = tree.NewBundle(nil, []int{2})
+	assert.Error(t, err, "an occupied index cannot be excluded")
+
+	bundle, err := tree.NewBundle([]int{2}, []int{5})
+	assert.NoError(t, err)
+	assert.NoError(t, bundle.SignHead(private))
+
+	bundle.Inclusions[0].Leaf = big.NewInt(8)
+	encoded, _ := json.Marshal(bundle)
+	_, err = VerifyBundle(encoded, zeroLeaf, public)
+	assert.Error(t, err)
+
+	bundle.Inclusions[0].Leaf = big.NewInt(7)
+	bundle.Exclusions[0].Index = 2
+	encoded, _ = json.Marshal(bundle)
+	_, err = VerifyBundle(encoded, zeroLeaf, public)
+	assert.Error(t, err)
+
+	bundle.Exclusions[0] = BundleProof{Index: 2, Path: bundle.Inclusions[0].Path}
+	bundle.Head.ZeroLeaf = big.NewInt(7)
+	assert.NoError(t, bundle.SignHead(private))
+	encoded, _ = json.Marshal(bundle)
+	_, err = VerifyBundle(encoded, zeroLeaf, public)
+	assert.Error(t, err, "a member's value cannot be passed off as the zero leaf")
+
+	_, err = VerifyBundle([]byte("not a bundle"), zeroLeaf, public)
+	assert.Error(t, err)
+}
+
+func TestNewBundleIndexOutOfRange(t *testing.T) {
+	tree := NewSparseMerkleTree(4, zeroLeaf)
+	tree.Insert(1, big.NewInt(10))
+
+	for _, index := range []int{-1, 16, 1 << 10} {
+		_, err := tree.NewBundle(nil, []int{index})
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+		_, err = tree.NewBundle([]int{index}, nil)
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	}
+}
@@ -0,0 +1,151 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// ErrCapacityExceeded is returned when a write would populate more leaves
+// than the capacity of the tree allows.
+var ErrCapacityExceeded = errors.New("tree capacity exceeded")
+
+// CapacityConfig bounds the number of populated leaves of a tree, that is
+// leaves holding a value other than the zero leaf, as counted by Len.
+type CapacityConfig struct {
+	MaxLeaves    int                    // Maximum number of populated leaves, or 0 for no limit.
+	MaxOccupancy float64                // Maximum fraction of the 2^depth indices that may be populated, or 0 for no limit.
+	Thresholds   []float64              // Fractions of the capacity at which OnWarning is called, such as 0.8 and 0.95.
+	OnWarning    func(OccupancyWarning) // Called under the tree's lock when a commit reaches a threshold, so it must not call back into the tree.
+}
+
+// OccupancyWarning reports that a commit filled a tree up to a threshold of
+// its capacity.
+type OccupancyWarning struct {
+	Head      TreeHead // Head of the commit that reached the threshold.
+	Leaves    int      // Number of populated leaves.
+	Capacity  int      // Maximum number of populated leaves.
+	Threshold float64  // Threshold reached.
+}
+
+// capacity is the capacity configuration of a tree.
+type capacity struct {
+	config      CapacityConfig
+	maxLeaves   int // Effective maximum number of populated leaves, or 0 for no limit.
+	lastWarning int // Number of thresholds already reported.
+}
+
+// SetCapacity bounds the number of populated leaves of the tree. Writes that
+// would exceed the capacity fail with ErrCapacityExceeded and leave the tree
+// unchanged; writes that keep or reduce the number of populated leaves are
+// always allowed. Tombstones count as populated leaves until purged. It
+// returns ErrCapacityExceeded if the tree is already over the new capacity.
+func (smt *SparseMerkleTree) SetCapacity(config CapacityConfig) error {
+	if config.MaxLeaves < 0 || config.MaxOccupancy < 0 || config.MaxOccupancy > 1 {
+		return fmt.Errorf("invalid capacity: %d leaves, occupancy %g", config.MaxLeaves, config.MaxOccupancy)
+	}
+	thresholds := append([]float64(nil), config.Thresholds...)
+	sort.Float64s(thresholds)
+	for _, threshold := range thresholds {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("invalid occupancy threshold: %g", threshold)
+		}
+	}
+	config.Thresholds = thresholds
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	limit := config.MaxLeaves
+	if config.MaxOccupancy > 0 {
+		byOccupancy := math.Floor(config.MaxOccupancy * math.Pow(2, float64(smt.depth)))
+		if byOccupancy < float64(math.MaxInt) && (limit == 0 || int(byOccupancy) < limit) {
+			limit = int(byOccupancy)
+		}
+	}
+	if limit > 0 && smt.nonDefaultLeaves > limit {
+		return fmt.Errorf("%w: tree holds %d leaves, more than %d", ErrCapacityExceeded, smt.nonDefaultLeaves, limit)
+	}
+
+	smt.capacity = &capacity{config: config, maxLeaves: limit}
+	smt.capacity.lastWarning = smt.capacity.reached(smt.nonDefaultLeaves)
+	return nil
+}
+
+// growth returns how many more leaves would be populated after setting the
+// leaf with the given binary key to value. The caller must hold the lock.
+func (smt *SparseMerkleTree) growth(key string, value *big.Int) int {
+	growth := 0
+	if value.Cmp(smt.zeroLeaf) != 0 {
+		growth++
+	}
+	if old, exists := smt.leaves[key]; exists && old.Cmp(smt.zeroLeaf) != 0 {
+		growth--
+	}
+	return growth
+}
+
+// checkGrowth returns ErrCapacityExceeded if populating growth more leaves
+// would exceed the capacity of the tree. The caller must hold the lock.
+func (smt *SparseMerkleTree) checkGrowth(growth int) error {
+	if smt.capacity == nil || smt.capacity.maxLeaves == 0 || growth <= 0 {
+		return nil
+	}
+	if smt.nonDefaultLeaves+growth > smt.capacity.maxLeaves {
+		return fmt.Errorf("%w: %d leaves plus %d exceed %d", ErrCapacityExceeded, smt.nonDefaultLeaves, growth, smt.capacity.maxLeaves)
+	}
+	return nil
+}
+
+// checkCapacity returns ErrCapacityExceeded if setting the leaves with the
+// given binary keys to values would exceed the capacity of the tree. The
+// caller must hold the lock.
+func (smt *SparseMerkleTree) checkCapacity(values map[string]*big.Int) error {
+	if smt.capacity == nil {
+		return nil
+	}
+	growth := 0
+	for key, value := range values {
+		growth += smt.growth(key, value)
+	}
+	return smt.checkGrowth(growth)
+}
+
+// warnOccupancy calls the warning callback for every threshold the commit
+// producing head reached. The caller must hold the write lock.
+func (smt *SparseMerkleTree) warnOccupancy(head TreeHead) {
+	c := smt.capacity
+	if c == nil {
+		return
+	}
+	reached := c.reached(smt.nonDefaultLeaves)
+	for i := c.lastWarning; i < reached; i++ {
+		if c.config.OnWarning != nil {
+			c.config.OnWarning(OccupancyWarning{Head: head, Leaves: smt.nonDefaultLeaves, Capacity: c.maxLeaves, Threshold: c.config.Thresholds[i]})
+		}
+	}
+	c.lastWarning = reached
+}
+
+// clone returns a copy of the capacity without its warning callback, or nil
+// for a nil capacity.
+func (c *capacity) clone() *capacity {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	clone.config.OnWarning = nil
+	return &clone
+}
+
+// reached returns the number of thresholds reached with the given number of
+// populated leaves.
+func (c *capacity) reached(leaves int) int {
+	if c.maxLeaves == 0 {
+		return 0
+	}
+	occupancy := float64(leaves) / float64(c.maxLeaves)
+	return sort.Search(len(c.config.Thresholds), func(i int) bool { return c.config.Thresholds[i] > occupancy })
+}
@@ -0,0 +1,71 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapacity(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	var warnings []OccupancyWarning
+	assert.NoError(t, smt.SetCapacity(CapacityConfig{
+		MaxLeaves:  4,
+		Thresholds: []float64{0.75, 0.5},
+		OnWarning:  func(w OccupancyWarning) { warnings = append(warnings, w) },
+	}))
+
+	assert.NoError(t, smt.Insert(0, big.NewInt(1)))
+	assert.NoError(t, smt.Insert(1, big.NewInt(2)))
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, 0.5, warnings[0].Threshold)
+	assert.Equal(t, 2, warnings[0].Leaves)
+	assert.Equal(t, smt.Head(), warnings[0].Head)
+
+	_, err := smt.BatchInsert([]LeafUpdate{{Index: 2, Value: big.NewInt(3)}, {Index: 3, Value: big.NewInt(4)}})
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, 0.75, warnings[1].Threshold)
+
+	root := smt.Root()
+	assert.ErrorIs(t, smt.Insert(4, big.NewInt(5)), ErrCapacityExceeded)
+	_, err = smt.ApplyAtomic([]Mutation{{Index: 0, Value: zeroLeaf}, {Index: 4, Value: big.NewInt(5)}, {Index: 5, Value: big.NewInt(6)}})
+	assert.ErrorIs(t, err, ErrCapacityExceeded)
+	assert.Equal(t, root, smt.Root(), "a rejected write leaves the tree unchanged")
+
+	assert.NoError(t, smt.Insert(0, big.NewInt(7)), "overwriting a populated leaf does not grow the tree")
+	assert.NoError(t, smt.Delete(0))
+	assert.NoError(t, smt.Delete(1))
+	assert.NoError(t, smt.Insert(4, big.NewInt(5)))
+	assert.Len(t, warnings, 3, "falling below a threshold rearms it")
+
+	assert.ErrorIs(t, smt.SetCapacity(CapacityConfig{MaxLeaves: 2}), ErrCapacityExceeded)
+	assert.Error(t, smt.SetCapacity(CapacityConfig{Thresholds: []float64{1.5}}))
+
+	clone := smt.Clone()
+	assert.NoError(t, clone.Insert(5, big.NewInt(6)))
+	assert.ErrorIs(t, clone.Insert(6, big.NewInt(7)), ErrCapacityExceeded)
+	assert.Len(t, warnings, 3, "a clone does not warn")
+}
+
+func TestCapacityOccupancy(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, smt.SetCapacity(CapacityConfig{MaxOccupancy: 0.25}))
+	assert.NoError(t, smt.Insert(0, big.NewInt(1)))
+	assert.NoError(t, smt.Insert(7, big.NewInt(2)))
+	assert.ErrorIs(t, smt.Insert(3, big.NewInt(3)), ErrCapacityExceeded)
+	assert.NoError(t, smt.Insert(3, zeroLeaf), "writing the zero leaf does not populate a leaf")
+}
+
+func TestCapacityTombstone(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, smt.SetTombstone(big.NewInt(99)))
+	assert.NoError(t, smt.SetCapacity(CapacityConfig{MaxLeaves: 1}))
+	assert.NoError(t, smt.Insert(0, big.NewInt(1)))
+	assert.NoError(t, smt.Insert(1, zeroLeaf))
+
+	assert.NoError(t, smt.Delete(0), "entombing a populated leaf does not grow the tree")
+	assert.ErrorIs(t, smt.Delete(1), ErrCapacityExceeded)
+	assert.Equal(t, zeroLeaf, smt.leaves[getPaddedBinaryString(1, 3)])
+}
@@ -0,0 +1,75 @@
+package smt
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// BatchCircuitInput holds the inputs of a circuit that verifies a fixed
+// number of inclusion proofs against one root in a single SNARK. Field
+// elements are decimal strings and paths run from the leaf up, as in
+// CircomCodec. Unused slots are dummy proofs with Enabled set to 0, which the
+// circuit must skip.
+type BatchCircuitInput struct {
+	Root        string     `json:"root"`        // Root all enabled proofs are verified against.
+	Enabled     []int      `json:"enabled"`     // 1 for a real proof, 0 for padding.
+	Keys        []string   `json:"keys"`        // Index of each leaf.
+	Leaves      []string   `json:"leaves"`      // Value of each leaf.
+	Siblings    [][]string `json:"siblings"`    // Sibling hashes of each path.
+	PathIndices [][]int    `json:"pathIndices"` // 1 where the path node is a right child, 0 otherwise.
+}
+
+// BatchCircuitInputs returns the inputs of a batch verification circuit of
+// size k proving the inclusion of the leaves at indices, padded with dummy
+// proofs. It returns ErrBatchTooLarge if there are more indices than k.
+func (smt *SparseMerkleTree) BatchCircuitInputs(indices []int, k int) (*BatchCircuitInput, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("invalid batch size: %d", k)
+	}
+	if len(indices) > k {
+		return nil, fmt.Errorf("%w: %d proofs for a circuit of %d", ErrBatchTooLarge, len(indices), k)
+	}
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	input := &BatchCircuitInput{
+		Root:        smt.root.Data.String(),
+		Enabled:     make([]int, k),
+		Keys:        make([]string, k),
+		Leaves:      make([]string, k),
+		Siblings:    make([][]string, k),
+		PathIndices: make([][]int, k),
+	}
+	for slot := 0; slot < k; slot++ {
+		siblings, pathIndices := make([]string, smt.depth), make([]int, smt.depth)
+		input.Siblings[slot], input.PathIndices[slot] = siblings, pathIndices
+		if slot >= len(indices) {
+			input.Keys[slot], input.Leaves[slot] = "0", "0"
+			for i := range siblings {
+				siblings[i] = "0"
+			}
+			continue
+		}
+
+		index := indices[slot]
+		if err := checkIndex(index, smt.depth); err != nil {
+			return nil, err
+		}
+		key := getPaddedBinaryString(index, smt.depth)
+		value, exists := smt.leaves[key]
+		if !exists {
+			return nil, fmt.Errorf("no leaf exists at key: %s", key)
+		}
+
+		input.Enabled[slot] = 1
+		input.Keys[slot] = strconv.Itoa(index)
+		input.Leaves[slot] = value.String()
+		path := smt.generateMerklePath(smt.root, key)
+		for i, item := range path {
+			siblings[i] = item.SiblingHash.String()
+		}
+		copy(pathIndices, PathIndices(path))
+	}
+	return input, nil
+}
@@ -0,0 +1,39 @@
+package smt
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchCircuitInputs(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	assert.NoError(t, smt.Insert(9, big.NewInt(90)))
+
+	input, err := smt.BatchCircuitInputs([]int{9, 3}, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 1, 0, 0}, input.Enabled)
+	assert.Equal(t, []string{"9", "3", "0", "0"}, input.Keys)
+	assert.Equal(t, smt.Root().String(), input.Root)
+
+	root, _ := new(big.Int).SetString(input.Root, 10)
+	for slot := 0; slot < 2; slot++ {
+		proof, err := json.Marshal(map[string]interface{}{"siblings": input.Siblings[slot], "pathIndices": input.PathIndices[slot]})
+		assert.NoError(t, err)
+		path, err := CircomCodec{}.DecodeProof(proof)
+		assert.NoError(t, err)
+		leaf, _ := new(big.Int).SetString(input.Leaves[slot], 10)
+		assert.True(t, VerifyMerklePath(leaf, path, root))
+	}
+	assert.Len(t, input.Siblings[3], 4)
+
+	_, err = smt.BatchCircuitInputs([]int{3, 9}, 1)
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+	_, err = smt.BatchCircuitInputs([]int{5}, 2)
+	assert.Error(t, err)
+	_, err = smt.BatchCircuitInputs([]int{16}, 2)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
@@ -0,0 +1,164 @@
+package smt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Claim is a statement about a tree that can be checked against a trusted
+// root with Verify. It is implemented by MembershipClaim, NonMembershipClaim
+// and TransitionClaim.
+type Claim interface {
+	claimType() string
+}
+
+// MembershipClaim states that the leaf at Index holds Leaf.
+type MembershipClaim struct {
+	Index int               `json:"index"` // Index of the leaf.
+	Leaf  *big.Int          `json:"leaf"`  // Value of the leaf.
+	Path  []*MerklePathItem `json:"path"`  // Merkle path of the leaf.
+}
+
+// NonMembershipClaim states that the leaf at Index holds the zero leaf. The
+// zero leaf is supplied by the verifier rather than the claim.
+type NonMembershipClaim struct {
+	Index int               `json:"index"` // Index of the leaf.
+	Path  []*MerklePathItem `json:"path"`  // Merkle path of the leaf.
+}
+
+// TransitionClaim states that setting one leaf moved the tree from the trusted
+// root to Proof.NewRoot.
+type TransitionClaim struct {
+	Proof TransitionProof `json:"proof"` // Proof of the transition.
+}
+
+func (*MembershipClaim) claimType() string    { return "membership" }
+func (*NonMembershipClaim) claimType() string { return "non-membership" }
+func (*TransitionClaim) claimType() string    { return "transition" }
+
+// Verify checks claim against trustedRoot. Non-membership claims are checked
+// against zeroLeaf, the zero leaf of the trusted tree, which is ignored for
+// other claims. Transition claims are checked against their old root, which
+// must equal trustedRoot.
+func Verify(claim Claim, trustedRoot, zeroLeaf *big.Int) error {
+	switch c := claim.(type) {
+	case *MembershipClaim:
+		if c == nil {
+			return fmt.Errorf("%w: nil membership claim", ErrMalformedInput)
+		}
+		if !verifyClaimPath(c.Index, c.Leaf, c.Path, trustedRoot) {
+			return fmt.Errorf("invalid membership claim for index %d", c.Index)
+		}
+	case *NonMembershipClaim:
+		if c == nil {
+			return fmt.Errorf("%w: nil non-membership claim", ErrMalformedInput)
+		}
+		if !verifyClaimPath(c.Index, zeroLeaf, c.Path, trustedRoot) {
+			return fmt.Errorf("invalid non-membership claim for index %d", c.Index)
+		}
+	case *TransitionClaim:
+		if c == nil {
+			return fmt.Errorf("%w: nil transition claim", ErrMalformedInput)
+		}
+		p := c.Proof
+		if p.OldRoot == nil || p.OldRoot.Cmp(trustedRoot) != 0 {
+			return fmt.Errorf("transition claim for index %d does not start at the trusted root", p.Index)
+		}
+		if p.NewLeaf == nil || p.NewRoot == nil || !verifyClaimPath(p.Index, p.OldLeaf, p.Path, p.OldRoot) || !VerifyMerklePath(p.NewLeaf, p.Path, p.NewRoot) {
+			return fmt.Errorf("invalid transition claim for index %d", p.Index)
+		}
+	default:
+		return fmt.Errorf("unsupported claim type: %T", claim)
+	}
+	return nil
+}
+
+// verifyClaimPath verifies a Merkle path for the leaf at index against root.
+func verifyClaimPath(index int, leaf *big.Int, path []*MerklePathItem, root *big.Int) bool {
+	if leaf == nil || root == nil || !pathMatchesIndex(path, index) {
+		return false
+	}
+	for _, item := range path {
+		if item.SiblingHash == nil {
+			return false
+		}
+	}
+	return VerifyMerklePath(leaf, path, root)
+}
+
+// encodedClaim is the canonical envelope of a serialized claim.
+type encodedClaim struct {
+	Type  string          `json:"type"`
+	Claim json.RawMessage `json:"claim"`
+}
+
+// EncodeClaim serializes claim into its canonical form: a JSON object with
+// the claim type and the claim, with numbers in decimal and fields in a
+// fixed order.
+func EncodeClaim(claim Claim) ([]byte, error) {
+	if claim == nil {
+		return nil, fmt.Errorf("nil claim")
+	}
+	body, err := json.Marshal(claim)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encodedClaim{Type: claim.claimType(), Claim: body})
+}
+
+// DecodeClaim parses a claim serialized by EncodeClaim.
+func DecodeClaim(data []byte) (Claim, error) {
+	var envelope encodedClaim
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid claim: %w", err)
+	}
+
+	var claim Claim
+	switch envelope.Type {
+	case "membership":
+		claim = &MembershipClaim{}
+	case "non-membership":
+		claim = &NonMembershipClaim{}
+	case "transition":
+		claim = &TransitionClaim{}
+	default:
+		return nil, fmt.Errorf("unsupported claim type: %q", envelope.Type)
+	}
+
+	if err := json.Unmarshal(envelope.Claim, claim); err != nil {
+		return nil, fmt.Errorf("invalid %s claim: %w", envelope.Type, err)
+	}
+	return claim, nil
+}
+
+// MembershipClaim returns a claim that the leaf at index holds its current value.
+func (smt *SparseMerkleTree) MembershipClaim(index int) (*MembershipClaim, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	leaf, exists := smt.leaves[key]
+	if !exists {
+		return nil, fmt.Errorf("no leaf exists at key: %s", key)
+	}
+	return &MembershipClaim{Index: index, Leaf: copyInt(leaf), Path: smt.generateMerklePath(smt.root, key)}, nil
+}
+
+// NonMembershipClaim returns a claim that the leaf at index is empty.
+func (smt *SparseMerkleTree) NonMembershipClaim(index int) (*NonMembershipClaim, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	if _, exists := smt.leaves[key]; exists {
+		return nil, fmt.Errorf("leaf exists at key: %s", key)
+	}
+	return &NonMembershipClaim{Index: index, Path: smt.generateMerklePath(smt.root, key)}, nil
+}
@@ -0,0 +1,53 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyClaims(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(2, big.NewInt(20))
+	root := smt.root.Data
+
+	membership, err := smt.MembershipClaim(2)
+	assert.NoError(t, err)
+	nonMembership, err := smt.NonMembershipClaim(3)
+	assert.NoError(t, err)
+	_, transitions, err := smt.SimulateBatch([]Mutation{{Index: 3, Value: big.NewInt(30)}})
+	assert.NoError(t, err)
+	transition := &TransitionClaim{Proof: transitions[0]}
+
+	for _, claim := range []Claim{membership, nonMembership, transition} {
+		assert.NoError(t, Verify(claim, root, zeroLeaf))
+		assert.Error(t, Verify(claim, zeroLeaf, zeroLeaf))
+
+		encoded, err := EncodeClaim(claim)
+		assert.NoError(t, err)
+		decoded, err := DecodeClaim(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, claim, decoded)
+		assert.NoError(t, Verify(decoded, root, zeroLeaf))
+
+		reencoded, err := EncodeClaim(decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, encoded, reencoded, "encoding must be canonical")
+	}
+
+	_, err = smt.MembershipClaim(3)
+	assert.Error(t, err)
+	_, err = smt.NonMembershipClaim(2)
+	assert.Error(t, err)
+
+	forged := &NonMembershipClaim{Index: 2, Path: membership.Path}
+	assert.Error(t, Verify(forged, root, zeroLeaf), "a member cannot be claimed empty")
+	assert.Error(t, Verify(nonMembership, root, nil))
+
+	membership.Index = 3
+	assert.Error(t, Verify(membership, root, zeroLeaf))
+
+	_, err = DecodeClaim([]byte(`{"type":"unknown","claim":{}}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package smt
+
+import "math/big"
+
+// Clone returns an independent copy of the tree for speculative updates that
+// may be discarded. The copy keeps the version history, key metadata, hash
+// pool and capacity of the tree but not its publishers, watchers or
+// occupancy warnings, so commits to the copy are not announced. Nodes and
+// leaf values are shared, since they are never modified; each tree copies
+// the nodes it changes.
+func (smt *SparseMerkleTree) Clone() *SparseMerkleTree {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	clone := &SparseMerkleTree{
+		root:             smt.root,
+		depth:            smt.depth,
+		leaves:           make(map[string]*big.Int, len(smt.leaves)),
+		zeroLeaf:         smt.zeroLeaf,
+		hasher:           smt.hasher,
+		compressed:       smt.compressed,
+		tombstone:        smt.tombstone,
+		nonDefaultLeaves: smt.nonDefaultLeaves,
+		capacity:         smt.capacity.clone(),
+		emptyHashes:      smt.emptyHashes,
+		version:          smt.version,
+		committedAt:      smt.committedAt,
+		recentHeads:      append([]TreeHead(nil), smt.recentHeads...),
+		heights:          append([]heightTag(nil), smt.heights...),
+		commitStats:      append([]CommitStats(nil), smt.commitStats...),
+		hashPool:         smt.hashPool,
+		keyCodec:         smt.keyCodec,
+	}
+	for key, value := range smt.leaves {
+		clone.leaves[key] = value
+	}
+	for key, preimage := range smt.preimages {
+		if clone.preimages == nil {
+			clone.preimages = make(map[string][]*big.Int, len(smt.preimages))
+		}
+		clone.preimages[key] = preimage
+	}
+	for key, owner := range smt.hashedKeys {
+		if clone.hashedKeys == nil {
+			clone.hashedKeys = make(map[string]hashedKey, len(smt.hashedKeys))
+		}
+		clone.hashedKeys[key] = owner
+	}
+	for key, name := range smt.keyNames {
+		clone.setKeyName(key, name)
+	}
+	return clone
+}
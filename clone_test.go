@@ -0,0 +1,33 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	var published int
+	smt.AddRootPublisher(RootPublisherFunc(func(TreeHead) error { published++; return nil }), nil)
+	root := smt.Root()
+
+	clone := smt.Clone()
+	assert.Equal(t, root, clone.Root())
+	assert.Equal(t, smt.Head().Version, clone.Head().Version)
+
+	assert.NoError(t, clone.Insert(3, big.NewInt(31)))
+	assert.NoError(t, clone.Insert(200, big.NewInt(2)))
+	assert.NoError(t, clone.Delete(3))
+
+	assert.Equal(t, root, smt.Root(), "updates to the clone do not reach the original")
+	value, _ := smt.Get(3)
+	assert.Equal(t, big.NewInt(30), value)
+	assert.Equal(t, 0, published)
+
+	path, err := smt.GenerateMerklePath(3)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePath(big.NewInt(30), path, root))
+}
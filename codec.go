@@ -0,0 +1,77 @@
+package smt
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pycckuu/smt/verify"
+)
+
+// ProofCodec converts Merkle paths to and from a wire format.
+type ProofCodec = verify.ProofCodec
+
+// Built-in proof codecs, implemented in package verify.
+type (
+	BinaryCodec = verify.BinaryCodec // Compact native format.
+	JSONCodec   = verify.JSONCodec   // JSON array of path items.
+	CircomCodec = verify.CircomCodec // Circom-style siblings and pathIndices arrays.
+)
+
+// Names of the built-in proof codecs.
+const (
+	CodecBinary = "binary" // Compact native format, see BinaryCodec.
+	CodecJSON   = "json"   // JSON array of path items.
+	CodecCircom = "circom" // Circom-style siblings and pathIndices arrays.
+)
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]ProofCodec{
+		CodecBinary: BinaryCodec{},
+		CodecJSON:   JSONCodec{},
+		CodecCircom: CircomCodec{},
+	}
+)
+
+// RegisterProofCodec makes codec available under name. It returns an error if
+// the name is already taken.
+func RegisterProofCodec(name string, codec ProofCodec) error {
+	if codec == nil {
+		return fmt.Errorf("nil proof codec: %q", name)
+	}
+
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	if _, exists := codecs[name]; exists {
+		return fmt.Errorf("proof codec already registered: %q", name)
+	}
+	codecs[name] = codec
+	return nil
+}
+
+// LookupProofCodec returns the codec registered under name.
+func LookupProofCodec(name string) (ProofCodec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	codec, exists := codecs[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown proof codec: %q", name)
+	}
+	return codec, nil
+}
+
+// ProofCodecs returns the sorted names of all registered codecs.
+func ProofCodecs() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+package smt
+
+import (
+	"testing"
+
+	"github.com/pycckuu/smt/verify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofCodecs(t *testing.T) {
+	smt := NewDeterministicSparseMerkleTree(3, zeroLeaf)
+	path, err := smt.GenerateMerklePath(5)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{CodecBinary, CodecCircom, CodecJSON}, ProofCodecs())
+	for _, name := range ProofCodecs() {
+		codec, err := LookupProofCodec(name)
+		assert.NoError(t, err)
+
+		data, err := codec.EncodeProof(path)
+		assert.NoError(t, err, name)
+		decoded, err := codec.DecodeProof(data)
+		assert.NoError(t, err, name)
+		assert.Equal(t, path, decoded, name)
+	}
+
+	data, _ := BinaryCodec{}.EncodeProof(path)
+	assert.Len(t, data, 3*verify.BinaryItemSize)
+	_, err = BinaryCodec{}.DecodeProof(data[1:])
+	assert.Error(t, err)
+	data[0] = 2
+	_, err = BinaryCodec{}.DecodeProof(data)
+	assert.Error(t, err)
+
+	_, err = CircomCodec{}.DecodeProof([]byte(`{"siblings":["1"],"pathIndices":[]}`))
+	assert.Error(t, err)
+}
+
+func TestRegisterProofCodec(t *testing.T) {
+	assert.Error(t, RegisterProofCodec(CodecJSON, JSONCodec{}))
+	assert.Error(t, RegisterProofCodec("nil", nil))
+
+	assert.NoError(t, RegisterProofCodec("test-binary", BinaryCodec{}))
+	codec, err := LookupProofCodec("test-binary")
+	assert.NoError(t, err)
+	assert.Equal(t, BinaryCodec{}, codec)
+
+	codecsMu.Lock()
+	delete(codecs, "test-binary")
+	codecsMu.Unlock()
+
+	_, err = LookupProofCodec("ics23")
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package smt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// CompactMerklePath is a Merkle path that omits the siblings that are empty
+// subtree hashes. Bit i of Bitmask, counting from the leaf up, is set when
+// the sibling at height i is present in Siblings. Positions are not stored,
+// since they follow from the leaf index.
+type CompactMerklePath struct {
+	Depth    int        `json:"depth"`    // Length of the full path.
+	Bitmask  []byte     `json:"bitmask"`  // Which siblings are stored, least significant bit first.
+	Siblings []*big.Int `json:"siblings"` // Non-empty siblings, from the leaf up.
+}
+
+// GenerateCompactMerklePath generates the compact Merkle path of the leaf at
+// index, whether or not a leaf was inserted there.
+func (smt *SparseMerkleTree) GenerateCompactMerklePath(index int) (*CompactMerklePath, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	return compressPath(smt.generateMerklePath(smt.root, getPaddedBinaryString(index, smt.depth)), smt.emptyHashes), nil
+}
+
+// compressPath drops the siblings of path that equal the empty subtree hash
+// of their height.
+func compressPath(path []*MerklePathItem, emptyHashes []*big.Int) *CompactMerklePath {
+	compact := &CompactMerklePath{Depth: len(path), Bitmask: make([]byte, (len(path)+7)/8)}
+	for height, item := range path {
+		if item.SiblingHash.Cmp(emptyHashes[height]) != 0 {
+			compact.Bitmask[height/8] |= 1 << (height % 8)
+			compact.Siblings = append(compact.Siblings, item.SiblingHash)
+		}
+	}
+	return compact
+}
+
+// ExpandCompactMerklePath rebuilds the full Merkle path of the leaf at index
+// from a compact path, filling in the empty subtree hashes of a tree with the
+// given hasher and zero leaf.
+func ExpandCompactMerklePath(index int, compact *CompactMerklePath, hasher Hasher, zeroLeaf *big.Int) ([]*MerklePathItem, error) {
+	if compact == nil {
+		return nil, fmt.Errorf("%w: nil compact path", ErrMalformedInput)
+	}
+	if err := checkIndex(index, compact.Depth); err != nil {
+		return nil, err
+	}
+	if len(compact.Bitmask) != (compact.Depth+7)/8 {
+		return nil, fmt.Errorf("bitmask of %d bytes for depth %d", len(compact.Bitmask), compact.Depth)
+	}
+	emptyHashes, err := emptyHashesWith(compact.Depth, hasher, zeroLeaf)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([]*MerklePathItem, compact.Depth)
+	next := 0
+	for height := range path {
+		sibling := emptyHashes[height]
+		if compact.Bitmask[height/8]&(1<<(height%8)) != 0 {
+			if next == len(compact.Siblings) || compact.Siblings[next] == nil {
+				return nil, fmt.Errorf("missing sibling at height %d", height)
+			}
+			sibling = compact.Siblings[next]
+			next++
+		}
+		path[height] = &MerklePathItem{SiblingHash: sibling, IsRight: index>>height&1 == 0}
+	}
+	if next != len(compact.Siblings) {
+		return nil, fmt.Errorf("%d siblings for %d bitmask bits", len(compact.Siblings), next)
+	}
+	return path, nil
+}
+
+// VerifyCompactMerklePath verifies the compact Merkle path of the leaf at
+// index against the expected root of a Poseidon tree with the given zero
+// leaf.
+func VerifyCompactMerklePath(index int, leafHash *big.Int, compact *CompactMerklePath, zeroLeaf, expectedRoot *big.Int) bool {
+	path, err := ExpandCompactMerklePath(index, compact, PoseidonHasher, zeroLeaf)
+	return err == nil && VerifyMerklePath(leafHash, path, expectedRoot)
+}
+
+// MarshalBinary encodes the path as the depth in two big-endian bytes, the
+// bitmask, and each sibling as 32 big-endian bytes.
+func (p *CompactMerklePath) MarshalBinary() ([]byte, error) {
+	if p.Depth < 0 || p.Depth > 0xffff || len(p.Bitmask) != (p.Depth+7)/8 {
+		return nil, fmt.Errorf("invalid compact path of depth %d", p.Depth)
+	}
+	data := make([]byte, 2, 2+len(p.Bitmask)+32*len(p.Siblings))
+	binary.BigEndian.PutUint16(data, uint16(p.Depth))
+	data = append(data, p.Bitmask...)
+	for i, sibling := range p.Siblings {
+		if sibling == nil || !utils.CheckBigIntInField(sibling) {
+			return nil, fmt.Errorf("sibling %d is not a field element", i)
+		}
+		var hash [32]byte
+		sibling.FillBytes(hash[:])
+		data = append(data, hash[:]...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a path encoded by MarshalBinary.
+func (p *CompactMerklePath) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return fmt.Errorf("compact path too short: %d bytes", len(data))
+	}
+	depth := int(binary.BigEndian.Uint16(data))
+	maskSize := (depth + 7) / 8
+	if len(data) < 2+maskSize || (len(data)-2-maskSize)%32 != 0 {
+		return fmt.Errorf("invalid compact path length %d for depth %d", len(data), depth)
+	}
+
+	bitmask := append([]byte(nil), data[2:2+maskSize]...)
+	siblings := make([]*big.Int, 0, (len(data)-2-maskSize)/32)
+	for offset := 2 + maskSize; offset < len(data); offset += 32 {
+		sibling := new(big.Int).SetBytes(data[offset : offset+32])
+		if !utils.CheckBigIntInField(sibling) {
+			return fmt.Errorf("sibling %d is not a field element", len(siblings))
+		}
+		siblings = append(siblings, sibling)
+	}
+	*p = CompactMerklePath{Depth: depth, Bitmask: bitmask, Siblings: siblings}
+	return nil
+}
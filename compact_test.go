@@ -0,0 +1,56 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactMerklePath(t *testing.T) {
+	smt := NewSparseMerkleTree(32, zeroLeaf)
+	assert.NoError(t, smt.Insert(5, big.NewInt(50)))
+	assert.NoError(t, smt.Insert(4, big.NewInt(40)))
+	assert.NoError(t, smt.Insert(1<<20, big.NewInt(60)))
+
+	compact, err := smt.GenerateCompactMerklePath(5)
+	assert.NoError(t, err)
+	assert.Len(t, compact.Siblings, 2)
+	assert.True(t, VerifyCompactMerklePath(5, big.NewInt(50), compact, zeroLeaf, smt.Root()))
+	assert.False(t, VerifyCompactMerklePath(4, big.NewInt(50), compact, zeroLeaf, smt.Root()))
+	assert.False(t, VerifyCompactMerklePath(5, big.NewInt(51), compact, zeroLeaf, smt.Root()))
+
+	full, err := smt.GenerateMerklePath(5)
+	assert.NoError(t, err)
+	expanded, err := ExpandCompactMerklePath(5, compact, PoseidonHasher, zeroLeaf)
+	assert.NoError(t, err)
+	assert.Equal(t, full, expanded)
+
+	exclusion, err := smt.GenerateCompactMerklePath(7)
+	assert.NoError(t, err)
+	assert.True(t, VerifyCompactMerklePath(7, zeroLeaf, exclusion, zeroLeaf, smt.Root()))
+}
+
+func TestCompactMerklePathBinary(t *testing.T) {
+	smt := NewSparseMerkleTree(160, zeroLeaf)
+	assert.NoError(t, smt.Insert(9, big.NewInt(90)))
+	assert.NoError(t, smt.Insert(8, big.NewInt(80)))
+
+	compact, err := smt.GenerateCompactMerklePath(9)
+	assert.NoError(t, err)
+	data, err := compact.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, 2+20+32, len(data), "one stored sibling instead of 160")
+
+	var decoded CompactMerklePath
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, *compact, decoded)
+	assert.True(t, VerifyCompactMerklePath(9, big.NewInt(90), &decoded, zeroLeaf, smt.Root()))
+
+	assert.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]))
+
+	tampered := decoded
+	tampered.Siblings = nil
+	_, err = ExpandCompactMerklePath(9, &tampered, PoseidonHasher, zeroLeaf)
+	assert.Error(t, err)
+}
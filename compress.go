@@ -0,0 +1,74 @@
+package smt
+
+import "math/big"
+
+// In a compressed tree, a subtree holding a single leaf is stored as one node
+// carrying the key and value of the leaf, instead of a chain of nodes down to
+// the leaf. The node keeps the hash the full chain would have, so roots and
+// proofs are the same as for an uncompressed tree with the same leaves, while
+// a tree of depth 160 or 256 stores about twice as many nodes as leaves
+// instead of one branch of depth nodes per leaf. Nodes are expanded one level
+// at a time when a second leaf is inserted below them and lifted back up when
+// a delete leaves a single leaf below them.
+
+// isCompressed reports whether node stands for a subtree holding a single
+// leaf.
+func (node *MerkleNode) isCompressed() bool {
+	return node != nil && node.leafKey != ""
+}
+
+// leafNode returns the node of the leaf with the given key and value at the
+// bottom of the tree.
+func (smt *SparseMerkleTree) leafNode(key string, value *big.Int) *MerkleNode {
+	if !smt.compressed {
+		return &MerkleNode{Data: value}
+	}
+	return &MerkleNode{Data: value, leafKey: key, leafValue: value}
+}
+
+// compressedNode returns the node at the specified depth of a subtree holding
+// only the leaf with the given key and value, hashing the chain up from the
+// leaf with hash.
+func (smt *SparseMerkleTree) compressedNode(key string, value *big.Int, depth int, hash hashFunc) *MerkleNode {
+	data := value
+	for d := smt.depth - 1; d >= depth; d-- {
+		child := &MerkleNode{Data: data}
+		if getPathBit(key, d) == 0 {
+			data = hash(child, nil, smt.depth-d)
+		} else {
+			data = hash(nil, child, smt.depth-d)
+		}
+	}
+	return &MerkleNode{Data: data, leafKey: key, leafValue: value}
+}
+
+// expand returns an uncompressed copy of the compressed node at the specified
+// depth, with its leaf one level down. If descend is set, the caller is about
+// to insert on the side of the leaf and node itself is passed down as the
+// child to be expanded or replaced there; otherwise the child is hashed with
+// hash. The hash of the returned node is left for the caller to recompute.
+func (smt *SparseMerkleTree) expand(node *MerkleNode, depth int, descend bool, hash hashFunc) *MerkleNode {
+	child := node
+	if !descend {
+		child = smt.compressedNode(node.leafKey, node.leafValue, depth+1, hash)
+	}
+	if getPathBit(node.leafKey, depth) == 0 {
+		return &MerkleNode{Left: child}
+	}
+	return &MerkleNode{Right: child}
+}
+
+// lift returns node as a compressed node if one of its children is empty and
+// the other one is compressed. The caller must have rehashed node.
+func (smt *SparseMerkleTree) lift(node *MerkleNode) *MerkleNode {
+	only := node.Left
+	if only == nil {
+		only = node.Right
+	} else if node.Right != nil {
+		return node
+	}
+	if !only.isCompressed() {
+		return node
+	}
+	return &MerkleNode{Data: node.Data, leafKey: only.leafKey, leafValue: only.leafValue}
+}
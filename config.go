@@ -0,0 +1,156 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// ErrConfigFrozen is returned when changing a parameter that determines the
+// root or the leaf indices of a tree that already holds leaves.
+var ErrConfigFrozen = errors.New("tree configuration is frozen")
+
+// SetZeroLeaf changes the zero leaf of an empty tree. It returns
+// ErrConfigFrozen once the tree holds leaves; use Rekey to rebuild such a
+// tree under a new zero leaf.
+func (smt *SparseMerkleTree) SetZeroLeaf(zeroLeaf *big.Int) error {
+	if err := checkValue(zeroLeaf); err != nil {
+		return err
+	}
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := smt.checkUnfrozen("zero leaf"); err != nil {
+		return err
+	}
+	zeroLeaf = copyInt(zeroLeaf)
+	emptyHashes, err := emptyHashesWith(smt.depth, smt.hasher, zeroLeaf)
+	if err != nil {
+		return err
+	}
+	smt.zeroLeaf = copyInt(zeroLeaf)
+	smt.emptyHashes = emptyHashes
+	smt.root = &MerkleNode{Data: smt.emptyHashes[smt.depth]}
+	smt.commit()
+	return nil
+}
+
+// checkUnfrozen returns ErrConfigFrozen if the tree holds leaves. The caller
+// must hold the lock.
+func (smt *SparseMerkleTree) checkUnfrozen(setting string) error {
+	if len(smt.leaves) > 0 {
+		return fmt.Errorf("%w: cannot change %s of a tree with %d leaves", ErrConfigFrozen, setting, len(smt.leaves))
+	}
+	return nil
+}
+
+// Rekey rebuilds the tree under a new depth and zero leaf and returns the new
+// tree, leaving the original unchanged. Leaves inserted by hashed key or by
+// application key are moved to the index their key derives under the new
+// depth; other leaves keep their index, which must fit the new depth. Leaves
+// holding the old zero leaf are carried over unchanged.
+func (smt *SparseMerkleTree) Rekey(depth int, zeroLeaf *big.Int) (*SparseMerkleTree, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("invalid depth: %d", depth)
+	}
+	if err := checkValue(zeroLeaf); err != nil {
+		return nil, err
+	}
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	rekeyed, err := newTreeWithHasher(depth, zeroLeaf, smt.hasher)
+	if err != nil {
+		return nil, err
+	}
+	if err := smt.rebuildInto(rekeyed); err != nil {
+		return nil, err
+	}
+	return rekeyed, nil
+}
+
+// newTreeWithHasher creates an empty tree whose inner nodes are hashed with
+// hasher.
+func newTreeWithHasher(depth int, zeroLeaf *big.Int, hasher Hasher) (*SparseMerkleTree, error) {
+	zeroLeaf = copyInt(zeroLeaf)
+	emptyHashes, err := emptyHashesWith(depth, hasher, zeroLeaf)
+	if err != nil {
+		return nil, err
+	}
+	root := &MerkleNode{Data: emptyHashes[depth]}
+	return &SparseMerkleTree{root: root, depth: depth, leaves: make(map[string]*big.Int), zeroLeaf: zeroLeaf, hasher: hasher, emptyHashes: emptyHashes, committedAt: time.Now()}, nil
+}
+
+// rebuildInto inserts the leaves of the tree and their metadata into the
+// empty tree target as a single commit, hashing disjoint subtrees in
+// parallel. The caller must hold the lock.
+func (smt *SparseMerkleTree) rebuildInto(target *SparseMerkleTree) error {
+	target.keyCodec = smt.keyCodec
+	target.compressed = smt.compressed
+	target.tombstone = smt.tombstone
+	sources := make(map[string]string, len(smt.leaves))
+	for key, value := range smt.leaves {
+		newKey, err := smt.rekeyedKey(target, key)
+		if err != nil {
+			return err
+		}
+		if source, taken := sources[newKey]; taken {
+			return fmt.Errorf("%w: leaves %s and %s both move to %s", ErrKeyCollision, source, key, newKey)
+		}
+		sources[newKey] = key
+
+		target.setLeaf(newKey, value)
+		if preimage, exists := smt.preimages[key]; exists {
+			if target.preimages == nil {
+				target.preimages = make(map[string][]*big.Int)
+			}
+			target.preimages[newKey] = preimage
+		}
+		if owner, hashed := smt.hashedKeys[key]; hashed {
+			if target.hashedKeys == nil {
+				target.hashedKeys = make(map[string]hashedKey)
+			}
+			target.hashedKeys[newKey] = owner
+		}
+		if name, named := smt.keyNames[key]; named {
+			target.setKeyName(newKey, name)
+		}
+	}
+
+	keys := make([]string, 0, len(target.leaves))
+	for key := range target.leaves {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	target.applyKeys(keys, target.leaves, 0)
+	target.commit()
+	return nil
+}
+
+// rekeyedKey returns the binary key in rekeyed of the leaf with the given
+// binary key. The caller must hold the lock.
+func (smt *SparseMerkleTree) rekeyedKey(rekeyed *SparseMerkleTree, key string) (string, error) {
+	if owner, hashed := smt.hashedKeys[key]; hashed {
+		return derivedKey(owner.domain, owner.id, rekeyed.depth), nil
+	}
+	if name, named := smt.keyNames[key]; named {
+		index, err := rekeyed.keyIndex(name)
+		if err != nil {
+			return "", fmt.Errorf("rekeying %q: %w", name, err)
+		}
+		return getPaddedBinaryString(index, rekeyed.depth), nil
+	}
+
+	index, err := getIndexFromBinaryString(key)
+	if err != nil {
+		return "", err
+	}
+	if err := checkIndex(index, rekeyed.depth); err != nil {
+		return "", fmt.Errorf("rekeying leaf %d: %w", index, err)
+	}
+	return getPaddedBinaryString(index, rekeyed.depth), nil
+}
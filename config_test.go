@@ -0,0 +1,54 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrozenConfig(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	other := big.NewInt(7)
+
+	assert.NoError(t, smt.SetZeroLeaf(other))
+	expected, _ := EmptyRoot(4, nil, other)
+	assert.Equal(t, expected, smt.Root())
+
+	assert.NoError(t, smt.Insert(1, big.NewInt(1)))
+	assert.ErrorIs(t, smt.SetZeroLeaf(zeroLeaf), ErrConfigFrozen)
+	assert.ErrorIs(t, smt.SetKeyCodec(DecimalKeyCodec{}), ErrConfigFrozen)
+	assert.Equal(t, other, smt.ZeroLeaf())
+}
+
+func TestRekey(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	_, err := smt.InsertHashed(KeyDomainBytes, []byte("alice"), big.NewInt(1))
+	assert.NoError(t, err)
+	_, err = smt.InsertKey("bob", big.NewInt(2))
+	assert.NoError(t, err)
+	root := smt.Root()
+
+	rekeyed, err := smt.Rekey(16, big.NewInt(9))
+	assert.NoError(t, err)
+	assert.Equal(t, root, smt.Root(), "the original tree is unchanged")
+
+	expected := NewSparseMerkleTree(16, big.NewInt(9))
+	assert.NoError(t, expected.Insert(3, big.NewInt(30)))
+	_, err = expected.InsertHashed(KeyDomainBytes, []byte("alice"), big.NewInt(1))
+	assert.NoError(t, err)
+	_, err = expected.InsertKey("bob", big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Root(), rekeyed.Root())
+
+	value, ok := rekeyed.GetHashed(KeyDomainBytes, []byte("alice"))
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(1), value)
+	index, _ := rekeyed.KeyIndex("bob")
+	name, _ := rekeyed.KeyName(index)
+	assert.Equal(t, "bob", name)
+
+	_, err = smt.Rekey(1, zeroLeaf)
+	assert.Error(t, err, "three leaves cannot fit a depth-1 tree")
+}
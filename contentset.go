@@ -0,0 +1,126 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// ContentSet is a sparse Merkle tree used as a set of field elements. Each
+// element is stored as the leaf at an index derived from its own hash, so
+// callers never allocate indices. Two elements deriving the same index
+// cannot both be members; the second one fails with ErrKeyCollision.
+type ContentSet struct {
+	tree *SparseMerkleTree
+}
+
+// ContentProof proves that Value is or is not a member of a content set.
+// Leaf is the value at the index derived from Value: Value itself for a
+// member, and the zero leaf or a colliding element otherwise.
+type ContentProof struct {
+	Value  *big.Int          `json:"value"`  // Element the proof is about.
+	Member bool              `json:"member"` // Whether Value is a member.
+	Leaf   *big.Int          `json:"leaf"`   // Leaf at the index derived from Value.
+	Path   []*MerklePathItem `json:"path"`   // Merkle path of Leaf.
+}
+
+// NewContentSet creates an empty content set in a tree of the given depth.
+// The zero leaf cannot be a member, since it is indistinguishable from an
+// absent element.
+func NewContentSet(depth int, zeroLeaf *big.Int) *ContentSet {
+	return &ContentSet{tree: NewSparseMerkleTree(depth, zeroLeaf)}
+}
+
+// Tree returns the underlying sparse Merkle tree.
+func (s *ContentSet) Tree() *SparseMerkleTree {
+	return s.tree
+}
+
+// Root returns the root hash of the set.
+func (s *ContentSet) Root() *big.Int {
+	return s.tree.Root()
+}
+
+// Add adds value to the set and returns its index. Adding a member again is a
+// no-op. It returns ErrKeyCollision if the index of value holds another
+// element.
+func (s *ContentSet) Add(value *big.Int) (int, error) {
+	if err := checkValue(value); err != nil {
+		return 0, err
+	}
+	if value.Cmp(s.tree.zeroLeaf) == 0 {
+		return 0, fmt.Errorf("the zero leaf cannot be a set member")
+	}
+	index := contentIndex(value, s.tree.depth)
+
+	s.tree.mu.Lock()
+	defer s.tree.mu.Unlock()
+
+	key := getPaddedBinaryString(index, s.tree.depth)
+	if current := s.tree.leafOrZero(key); current.Cmp(value) == 0 {
+		return index, nil
+	} else if current.Cmp(s.tree.zeroLeaf) != 0 {
+		return 0, fmt.Errorf("%w: %s and %s both derive index %d", ErrKeyCollision, current, value, index)
+	}
+
+	if err := s.tree.set(key, value); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// Contains reports whether value is a member of the set.
+func (s *ContentSet) Contains(value *big.Int) bool {
+	if checkValue(value) != nil {
+		return false
+	}
+	current, exists := s.tree.Get(contentIndex(value, s.tree.depth))
+	return exists && current.Cmp(value) == 0 && value.Cmp(s.tree.zeroLeaf) != 0
+}
+
+// Prove returns a membership proof for value if it is in the set, and a
+// non-membership proof otherwise.
+func (s *ContentSet) Prove(value *big.Int) (*ContentProof, error) {
+	if err := checkValue(value); err != nil {
+		return nil, err
+	}
+	if value.Cmp(s.tree.zeroLeaf) == 0 {
+		return nil, fmt.Errorf("the zero leaf cannot be a set member")
+	}
+	index := contentIndex(value, s.tree.depth)
+
+	s.tree.mu.RLock()
+	defer s.tree.mu.RUnlock()
+
+	key := getPaddedBinaryString(index, s.tree.depth)
+	leaf := s.tree.leafOrZero(key)
+	return &ContentProof{
+		Value:  value,
+		Member: leaf.Cmp(value) == 0,
+		Leaf:   leaf,
+		Path:   s.tree.generateMerklePath(s.tree.root, key),
+	}, nil
+}
+
+// VerifyContentProof checks proof against the root of a content set with the
+// given zero leaf. A membership proof is valid if its leaf is the value at the
+// index derived from it; a non-membership proof is valid if that index holds
+// a different leaf.
+func VerifyContentProof(proof *ContentProof, root, zeroLeaf *big.Int) bool {
+	if proof == nil || proof.Value == nil || proof.Leaf == nil || root == nil || zeroLeaf == nil {
+		return false
+	}
+	if proof.Value.Cmp(zeroLeaf) == 0 || proof.Member != (proof.Leaf.Cmp(proof.Value) == 0) {
+		return false
+	}
+	index := contentIndex(proof.Value, len(proof.Path))
+	return pathMatchesIndex(proof.Path, index) && VerifyMerklePath(proof.Leaf, proof.Path, root)
+}
+
+// contentIndex returns the index of value in a content set of the given
+// depth.
+func contentIndex(value *big.Int, depth int) int {
+	h, _ := poseidon.Hash([]*big.Int{new(big.Int).SetUint64(uint64(KeyDomainContent)), value})
+	return indexFromHash(h, depth)
+}
@@ -0,0 +1,64 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentSet(t *testing.T) {
+	set := NewContentSet(16, zeroLeaf)
+
+	index, err := set.Add(big.NewInt(42))
+	assert.NoError(t, err)
+	assert.Equal(t, contentIndex(big.NewInt(42), 16), index)
+	again, err := set.Add(big.NewInt(42))
+	assert.NoError(t, err)
+	assert.Equal(t, index, again)
+	assert.Equal(t, 1, set.Tree().Head().Version, "adding a member again does not commit")
+
+	assert.True(t, set.Contains(big.NewInt(42)))
+	assert.False(t, set.Contains(big.NewInt(43)))
+
+	member, err := set.Prove(big.NewInt(42))
+	assert.NoError(t, err)
+	assert.True(t, member.Member)
+	assert.True(t, VerifyContentProof(member, set.Root(), zeroLeaf))
+
+	absent, err := set.Prove(big.NewInt(43))
+	assert.NoError(t, err)
+	assert.False(t, absent.Member)
+	assert.True(t, VerifyContentProof(absent, set.Root(), zeroLeaf))
+
+	forged := *absent
+	forged.Member = true
+	assert.False(t, VerifyContentProof(&forged, set.Root(), zeroLeaf))
+
+	_, err = set.Add(zeroLeaf)
+	assert.Error(t, err)
+	_, err = set.Prove(zeroLeaf)
+	assert.Error(t, err)
+}
+
+func TestContentSetCollision(t *testing.T) {
+	set := NewContentSet(2, zeroLeaf)
+	first := big.NewInt(1)
+	_, err := set.Add(first)
+	assert.NoError(t, err)
+
+	// Find another element deriving the same index in the 4-leaf tree.
+	other := big.NewInt(2)
+	for contentIndex(other, 2) != contentIndex(first, 2) {
+		other.Add(other, big.NewInt(1))
+	}
+	_, err = set.Add(other)
+	assert.ErrorIs(t, err, ErrKeyCollision)
+
+	// The colliding element is proven absent by the member holding its index.
+	proof, err := set.Prove(other)
+	assert.NoError(t, err)
+	assert.False(t, proof.Member)
+	assert.Equal(t, first, proof.Leaf)
+	assert.True(t, VerifyContentProof(proof, set.Root(), zeroLeaf))
+}
@@ -0,0 +1,74 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// EpochHead is the tree head that closed or currently represents an epoch.
+type EpochHead struct {
+	Epoch int      // Epoch number.
+	Head  TreeHead // Head of the epoch's tree.
+}
+
+// ContinuityProof shows that a leaf held the same value in the tree of one
+// epoch and in the tree of the following epoch.
+type ContinuityProof struct {
+	Index    int               // Index of the leaf in both trees.
+	Leaf     *big.Int          // Value of the leaf in both trees.
+	From     EpochHead         // Head of the earlier epoch.
+	FromPath []*MerklePathItem // Merkle path of the leaf in the earlier epoch.
+	To       EpochHead         // Head of the following epoch.
+	ToPath   []*MerklePathItem // Merkle path of the leaf in the following epoch.
+}
+
+// ProveContinuity proves that the leaf at index holds the same value in from,
+// the archived tree of epoch fromEpoch, and in to, the tree of the next epoch.
+func ProveContinuity(from *SparseMerkleTree, fromEpoch int, to *SparseMerkleTree, index int) (*ContinuityProof, error) {
+	fromClaim, err := from.MembershipClaim(index)
+	if err != nil {
+		return nil, fmt.Errorf("epoch %d: %w", fromEpoch, err)
+	}
+	toClaim, err := to.MembershipClaim(index)
+	if err != nil {
+		return nil, fmt.Errorf("epoch %d: %w", fromEpoch+1, err)
+	}
+	if fromClaim.Leaf.Cmp(toClaim.Leaf) != 0 {
+		return nil, fmt.Errorf("leaf at index %d changed between epochs %d and %d", index, fromEpoch, fromEpoch+1)
+	}
+
+	return &ContinuityProof{
+		Index:    index,
+		Leaf:     fromClaim.Leaf,
+		From:     EpochHead{Epoch: fromEpoch, Head: from.Head()},
+		FromPath: fromClaim.Path,
+		To:       EpochHead{Epoch: fromEpoch + 1, Head: to.Head()},
+		ToPath:   toClaim.Path,
+	}, nil
+}
+
+// VerifyContinuityProof verifies that proof links consecutive epochs whose
+// roots are the trusted fromRoot and toRoot, and that the leaf is included
+// at the same index under both.
+func VerifyContinuityProof(proof *ContinuityProof, fromRoot, toRoot *big.Int) error {
+	if proof == nil {
+		return fmt.Errorf("%w: nil continuity proof", ErrMalformedInput)
+	}
+	if proof.To.Epoch != proof.From.Epoch+1 {
+		return fmt.Errorf("epochs %d and %d are not consecutive", proof.From.Epoch, proof.To.Epoch)
+	}
+	if proof.From.Head.Root == nil || proof.From.Head.Root.Cmp(fromRoot) != 0 {
+		return fmt.Errorf("epoch %d head does not match the trusted root", proof.From.Epoch)
+	}
+	if proof.To.Head.Root == nil || proof.To.Head.Root.Cmp(toRoot) != 0 {
+		return fmt.Errorf("epoch %d head does not match the trusted root", proof.To.Epoch)
+	}
+
+	if err := Verify(&MembershipClaim{Index: proof.Index, Leaf: proof.Leaf, Path: proof.FromPath}, fromRoot, nil); err != nil {
+		return fmt.Errorf("epoch %d: %w", proof.From.Epoch, err)
+	}
+	if err := Verify(&MembershipClaim{Index: proof.Index, Leaf: proof.Leaf, Path: proof.ToPath}, toRoot, nil); err != nil {
+		return fmt.Errorf("epoch %d: %w", proof.To.Epoch, err)
+	}
+	return nil
+}
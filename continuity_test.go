@@ -0,0 +1,33 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContinuityProof(t *testing.T) {
+	epoch7 := NewSparseMerkleTree(3, zeroLeaf)
+	epoch7.Insert(1, big.NewInt(10))
+	epoch7.Insert(2, big.NewInt(20))
+
+	epoch8 := NewSparseMerkleTree(3, zeroLeaf)
+	epoch8.Insert(1, big.NewInt(10))
+	epoch8.Insert(2, big.NewInt(21))
+	epoch8.Insert(5, big.NewInt(50))
+
+	proof, err := ProveContinuity(epoch7, 7, epoch8, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, proof.To.Epoch)
+	assert.NoError(t, VerifyContinuityProof(proof, epoch7.root.Data, epoch8.root.Data))
+	assert.Error(t, VerifyContinuityProof(proof, epoch8.root.Data, epoch7.root.Data))
+
+	_, err = ProveContinuity(epoch7, 7, epoch8, 2)
+	assert.Error(t, err, "the leaf changed between epochs")
+	_, err = ProveContinuity(epoch7, 7, epoch8, 5)
+	assert.Error(t, err, "the leaf did not exist in the earlier epoch")
+
+	proof.To.Epoch = 9
+	assert.Error(t, VerifyContinuityProof(proof, epoch7.root.Data, epoch8.root.Data))
+}
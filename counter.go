@@ -0,0 +1,105 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrCounterDecrease is returned when a counter would move backwards.
+var ErrCounterDecrease = errors.New("counter decrease")
+
+// CounterTree is a sparse Merkle tree whose leaves are monotonic counters,
+// such as nonces in a replay-protection registry. Its zero leaf is 0, so an
+// untouched counter holds 0 and every counter value is its leaf. The
+// underlying tree is not exposed, so counters only move through Increment
+// and Advance.
+type CounterTree struct {
+	tree *SparseMerkleTree
+}
+
+// CounterProof proves the value of a counter, and so that it is at least any
+// bound not above that value.
+type CounterProof struct {
+	Index int               `json:"index"` // Index of the counter.
+	Value *big.Int          `json:"value"` // Value of the counter.
+	Path  []*MerklePathItem `json:"path"`  // Merkle path of the counter leaf.
+}
+
+// NewCounterTree creates a tree of 2^depth counters, all starting at 0.
+func NewCounterTree(depth int) *CounterTree {
+	return &CounterTree{tree: NewSparseMerkleTree(depth, big.NewInt(0))}
+}
+
+// Root returns the root hash of the tree.
+func (c *CounterTree) Root() *big.Int {
+	return c.tree.Root()
+}
+
+// Head returns the tree head of the current version of the tree.
+func (c *CounterTree) Head() TreeHead {
+	return c.tree.Head()
+}
+
+// Value returns the value of the counter at index.
+func (c *CounterTree) Value(index int) (*big.Int, error) {
+	return c.tree.Leaf(index)
+}
+
+// Get returns the value of the counter at index and reports whether it was
+// ever advanced.
+func (c *CounterTree) Get(index int) (*big.Int, bool) {
+	return c.tree.Get(index)
+}
+
+// Increment adds one to the counter at index and returns its new value.
+func (c *CounterTree) Increment(index int) (*big.Int, error) {
+	if err := checkIndex(index, c.tree.depth); err != nil {
+		return nil, err
+	}
+	return c.tree.Add(index, big.NewInt(1))
+}
+
+// Advance sets the counter at index to value, which must not be below its
+// current value; otherwise it returns ErrCounterDecrease.
+func (c *CounterTree) Advance(index int, value *big.Int) error {
+	if err := checkIndex(index, c.tree.depth); err != nil {
+		return err
+	}
+	if err := checkValue(value); err != nil {
+		return err
+	}
+
+	c.tree.mu.Lock()
+	defer c.tree.mu.Unlock()
+
+	key := getPaddedBinaryString(index, c.tree.depth)
+	if current := c.tree.leafOrZero(key); value.Cmp(current) < 0 {
+		return fmt.Errorf("%w at key %s: %s is below %s", ErrCounterDecrease, key, value, current)
+	} else if value.Cmp(current) == 0 {
+		return nil
+	}
+
+	return c.tree.set(key, value)
+}
+
+// Prove returns a proof of the current value of the counter at index.
+func (c *CounterTree) Prove(index int) (*CounterProof, error) {
+	if err := checkIndex(index, c.tree.depth); err != nil {
+		return nil, err
+	}
+
+	c.tree.mu.RLock()
+	defer c.tree.mu.RUnlock()
+
+	key := getPaddedBinaryString(index, c.tree.depth)
+	return &CounterProof{Index: index, Value: copyInt(c.tree.leafOrZero(key)), Path: c.tree.generateMerklePath(c.tree.root, key)}, nil
+}
+
+// VerifyCounterAtLeast checks that proof shows the counter at its index to be
+// at least n in the counter tree with the given root.
+func VerifyCounterAtLeast(proof *CounterProof, n, root *big.Int) bool {
+	return proof != nil && proof.Value != nil && n != nil && root != nil &&
+		proof.Value.Cmp(n) >= 0 && pathMatchesIndex(proof.Path, proof.Index) &&
+		VerifyMerklePath(proof.Value, proof.Path, root)
+}
@@ -0,0 +1,57 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterTree(t *testing.T) {
+	counters := NewCounterTree(8)
+
+	value, err := counters.Increment(5)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), value)
+	value, err = counters.Increment(5)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), value)
+
+	assert.NoError(t, counters.Advance(5, big.NewInt(10)))
+	version := counters.Head().Version
+	assert.NoError(t, counters.Advance(5, big.NewInt(10)))
+	assert.Equal(t, version, counters.Head().Version, "advancing to the current value does not commit")
+	assert.ErrorIs(t, counters.Advance(5, big.NewInt(9)), ErrCounterDecrease)
+
+	value, err = counters.Value(5)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), value)
+	value, ok := counters.Get(5)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(10), value)
+	_, ok = counters.Get(6)
+	assert.False(t, ok)
+
+	_, err = counters.Increment(256)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestCounterProof(t *testing.T) {
+	counters := NewCounterTree(8)
+	assert.NoError(t, counters.Advance(3, big.NewInt(7)))
+
+	proof, err := counters.Prove(3)
+	assert.NoError(t, err)
+	assert.True(t, VerifyCounterAtLeast(proof, big.NewInt(7), counters.Root()))
+	assert.True(t, VerifyCounterAtLeast(proof, big.NewInt(1), counters.Root()))
+	assert.False(t, VerifyCounterAtLeast(proof, big.NewInt(8), counters.Root()))
+
+	forged := *proof
+	forged.Value = big.NewInt(100)
+	assert.False(t, VerifyCounterAtLeast(&forged, big.NewInt(8), counters.Root()))
+
+	untouched, err := counters.Prove(4)
+	assert.NoError(t, err)
+	assert.True(t, VerifyCounterAtLeast(untouched, big.NewInt(0), counters.Root()))
+	assert.False(t, VerifyCounterAtLeast(untouched, big.NewInt(1), counters.Root()))
+}
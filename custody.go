@@ -0,0 +1,85 @@
+package smt
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrInvalidSignature is returned when a custody envelope is not signed by
+// the trusted key.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Provenance records where and when a proof bundle was generated.
+type Provenance struct {
+	Generator       string    `json:"generator"`       // Identity of the service that generated the bundle.
+	SoftwareVersion string    `json:"softwareVersion"` // Version of the generating software.
+	GeneratedAt     time.Time `json:"generatedAt"`     // Time the envelope was sealed.
+	Head            TreeHead  `json:"head"`            // Head of the tree the bundle was generated against.
+}
+
+// custodyPayload is the signed content of a custody envelope.
+type custodyPayload struct {
+	Provenance Provenance `json:"provenance"`
+	Bundle     *Bundle    `json:"bundle"`
+}
+
+// CustodyEnvelope is a proof bundle with its provenance, signed by the
+// generator so auditors can trace where the proofs came from.
+type CustodyEnvelope struct {
+	Payload   json.RawMessage `json:"payload"`   // Provenance and bundle, exactly as signed.
+	Signature []byte          `json:"signature"` // Ed25519 signature of Payload.
+}
+
+// SealBundle wraps bundle in an envelope recording the generator identity,
+// its software version, the current time and the bundle's tree head, signed
+// with key.
+func SealBundle(bundle *Bundle, generator, softwareVersion string, key ed25519.PrivateKey) (*CustodyEnvelope, error) {
+	if bundle == nil {
+		return nil, fmt.Errorf("nil bundle")
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid signing key size: %d", len(key))
+	}
+
+	payload, err := json.Marshal(custodyPayload{
+		Provenance: Provenance{Generator: generator, SoftwareVersion: softwareVersion, GeneratedAt: time.Now().UTC(), Head: bundle.Head},
+		Bundle:     bundle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CustodyEnvelope{Payload: payload, Signature: ed25519.Sign(key, payload)}, nil
+}
+
+// OpenEnvelope checks that envelope was signed by trusted, that its
+// provenance refers to the head of its bundle and that the bundle verifies
+// with exclusion proofs checked against the trusted zeroLeaf, and returns the
+// established claims with the provenance. The envelope signature covers the
+// bundle head, so the head itself need not be signed.
+func OpenEnvelope(envelope *CustodyEnvelope, trusted ed25519.PublicKey, zeroLeaf *big.Int) (Claims, Provenance, error) {
+	if envelope == nil || len(trusted) != ed25519.PublicKeySize || !ed25519.Verify(trusted, envelope.Payload, envelope.Signature) {
+		return Claims{}, Provenance{}, ErrInvalidSignature
+	}
+
+	var payload custodyPayload
+	if err := json.Unmarshal(envelope.Payload, &payload); err != nil {
+		return Claims{}, Provenance{}, fmt.Errorf("invalid envelope payload: %w", err)
+	}
+	if payload.Bundle == nil {
+		return Claims{}, Provenance{}, fmt.Errorf("invalid envelope payload: no bundle")
+	}
+
+	claims, err := verifyBundle(payload.Bundle, zeroLeaf)
+	if err != nil {
+		return Claims{}, Provenance{}, err
+	}
+	head := payload.Provenance.Head
+	if head.Root == nil || head.Root.Cmp(claims.Head.Root) != 0 || head.Version != claims.Head.Version {
+		return Claims{}, Provenance{}, fmt.Errorf("provenance refers to a different tree head than the bundle")
+	}
+	return claims, payload.Provenance, nil
+}
@@ -0,0 +1,48 @@
+package smt
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustodyEnvelope(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	bundle, err := smt.NewBundle([]int{3}, []int{4})
+	assert.NoError(t, err)
+
+	envelope, err := SealBundle(bundle, "prover-eu-1", "v1.4.2", private)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(envelope)
+	assert.NoError(t, err)
+	var received CustodyEnvelope
+	assert.NoError(t, json.Unmarshal(data, &received))
+
+	claims, provenance, err := OpenEnvelope(&received, public, zeroLeaf)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(30), claims.Members[3])
+	assert.Equal(t, "prover-eu-1", provenance.Generator)
+	assert.Equal(t, "v1.4.2", provenance.SoftwareVersion)
+	assert.Equal(t, smt.Head().Version, provenance.Head.Version)
+	assert.False(t, provenance.GeneratedAt.IsZero())
+
+	_, _, err = OpenEnvelope(&received, public, big.NewInt(1))
+	assert.Error(t, err)
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	_, _, err = OpenEnvelope(&received, other, zeroLeaf)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	received.Payload = append(json.RawMessage(nil), received.Payload...)
+	received.Payload[len(received.Payload)-2] ^= 1
+	_, _, err = OpenEnvelope(&received, public, zeroLeaf)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
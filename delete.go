@@ -0,0 +1,104 @@
+package smt
+
+import "math/big"
+
+// Delete removes the leaf at index, pruning the nodes left empty so that the
+// root returns to the value it would have if the leaf had never been
+// inserted. In tombstone mode, see SetTombstone, it overwrites the leaf with
+// the tombstone instead. Deleting an index that holds no leaf, or a
+// tombstone, is a no-op.
+func (smt *SparseMerkleTree) Delete(index int) error {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return err
+	}
+	return smt.deleteKey(getPaddedBinaryString(index, smt.depth))
+}
+
+// deleteKey implements Delete for the leaf with the given binary key. The
+// caller must hold the write lock.
+func (smt *SparseMerkleTree) deleteKey(key string) error {
+	value, exists := smt.leaves[key]
+	if !exists {
+		return nil
+	}
+
+	if smt.tombstone != nil {
+		if value.Cmp(smt.tombstone) == 0 {
+			return nil
+		}
+		return smt.entomb(key)
+	}
+	smt.delete(key)
+	smt.commit()
+	return nil
+}
+
+// Reset drops every leaf and restores the root of the empty tree in constant
+// time, keeping the configuration of the tree, its version history and its
+// publishers, so a long-lived tree can be reused for every epoch.
+func (smt *SparseMerkleTree) Reset() {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	smt.pendingStats.LeavesRemoved += len(smt.leaves)
+	smt.leaves = make(map[string]*big.Int)
+	smt.nonDefaultLeaves = 0
+	smt.preimages = nil
+	smt.hashedKeys = nil
+	smt.keyNames = nil
+	smt.dirtyKeys = nil
+	smt.root = &MerkleNode{Data: smt.emptyHashes[smt.depth]}
+	smt.commit()
+}
+
+// delete removes the leaf with the given binary key together with its
+// preimage, hashed key and key name. The caller must hold the write lock.
+func (smt *SparseMerkleTree) delete(key string) {
+	smt.pendingStats.LeavesRemoved++
+	if smt.leaves[key].Cmp(smt.zeroLeaf) != 0 {
+		smt.nonDefaultLeaves--
+	}
+	smt.markDirty(key)
+	delete(smt.leaves, key)
+	delete(smt.preimages, key)
+	delete(smt.hashedKeys, key)
+	delete(smt.keyNames, key)
+
+	smt.root = smt.deleteFromNode(smt.root, key, 0)
+	if smt.root == nil {
+		smt.root = &MerkleNode{Data: smt.emptyHashes[smt.depth]}
+	}
+}
+
+// deleteFromNode returns a copy of the subtree of node at the specified depth
+// without the leaf with the given key, or nil if the subtree becomes empty.
+func (smt *SparseMerkleTree) deleteFromNode(node *MerkleNode, key string, depth int) *MerkleNode {
+	if node == nil || depth == smt.depth {
+		return nil
+	}
+	if node.isCompressed() {
+		if node.leafKey == key {
+			return nil
+		}
+		return node
+	}
+
+	node = copyNode(node)
+	if getPathBit(key, depth) == 0 {
+		node.Left = smt.deleteFromNode(node.Left, key, depth+1)
+	} else {
+		node.Right = smt.deleteFromNode(node.Right, key, depth+1)
+	}
+	if node.Left == nil && node.Right == nil {
+		return nil
+	}
+
+	node.Data = smt.hashNode(node.Left, node.Right, smt.depth-depth)
+	if smt.compressed {
+		return smt.lift(node)
+	}
+	return node
+}
@@ -0,0 +1,56 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelete(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	empty := smt.root.Data
+
+	smt.Insert(3, big.NewInt(30))
+	withOne := smt.root.Data
+	smt.Insert(200, big.NewInt(2))
+
+	assert.NoError(t, smt.Delete(200))
+	assert.Equal(t, withOne, smt.root.Data)
+	assert.NotContains(t, smt.leaves, getPaddedBinaryString(200, 8))
+	assert.Nil(t, smt.root.Right, "empty subtrees are pruned")
+
+	path, err := smt.GenerateMerklePath(3)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePath(big.NewInt(30), path, smt.root.Data))
+
+	assert.NoError(t, smt.Delete(3))
+	assert.Equal(t, empty, smt.root.Data)
+	assert.Empty(t, smt.leaves)
+
+	version := smt.Head().Version
+	assert.NoError(t, smt.Delete(3))
+	assert.Equal(t, version, smt.Head().Version, "deleting an unset leaf does not commit")
+	assert.Error(t, smt.Delete(256))
+}
+
+func TestReset(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	empty := smt.Root()
+	index, err := smt.InsertKey("alice", big.NewInt(1))
+	assert.NoError(t, err)
+	assert.NoError(t, smt.Insert(7, big.NewInt(2)))
+	version := smt.Head().Version
+
+	smt.Reset()
+	assert.Equal(t, empty, smt.Root())
+	assert.Equal(t, 0, smt.Len())
+	assert.Equal(t, version+1, smt.Head().Version)
+	assert.Equal(t, 2, smt.CommitStats(version+1, version+1)[0].LeavesRemoved)
+
+	_, named := smt.KeyName(index)
+	assert.False(t, named)
+	path, err := smt.GenerateMerklePath(7)
+	assert.Error(t, err)
+	assert.Nil(t, path)
+}
@@ -0,0 +1,123 @@
+package smt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// KeyChange is a leaf whose value differs between two states of a tree.
+type KeyChange struct {
+	Index *big.Int `json:"index"`         // Index of the leaf.
+	Old   *big.Int `json:"old,omitempty"` // Value in the old state, omitted if unset.
+	New   *big.Int `json:"new,omitempty"` // Value in the new state, omitted if unset.
+}
+
+// SubtreeChange is a subtree whose root hash differs between two states of a
+// tree.
+type SubtreeChange struct {
+	Path   string   `json:"path"`   // Binary path of the subtree root, empty for the tree root.
+	Height int      `json:"height"` // Height of the subtree root above the leaves.
+	Old    *big.Int `json:"old"`    // Hash in the old state.
+	New    *big.Int `json:"new"`    // Hash in the new state.
+}
+
+// StateDiff describes the changes between two states of a tree.
+type StateDiff struct {
+	Depth    int             `json:"depth"`    // Depth of both trees.
+	OldRoot  *big.Int        `json:"oldRoot"`  // Root of the old state.
+	NewRoot  *big.Int        `json:"newRoot"`  // Root of the new state.
+	Keys     []KeyChange     `json:"keys"`     // Changed leaves, by index.
+	Subtrees []SubtreeChange `json:"subtrees"` // Changed subtree roots, top down.
+}
+
+// CompareTrees computes the changes that turn tree a into tree b. Both trees
+// must have the same depth and zero leaf.
+func CompareTrees(a, b *SparseMerkleTree) (*StateDiff, error) {
+	if a.depth != b.depth || a.zeroLeaf.Cmp(b.zeroLeaf) != 0 {
+		return nil, fmt.Errorf("trees differ in depth or zero leaf")
+	}
+
+	diff := &StateDiff{Depth: a.depth, Keys: []KeyChange{}, Subtrees: []SubtreeChange{}}
+	roots, err := a.SubtreeHashes([]string{""})
+	if err != nil {
+		return nil, err
+	}
+	diff.OldRoot = roots[0]
+	if roots, err = b.SubtreeHashes([]string{""}); err != nil {
+		return nil, err
+	}
+	diff.NewRoot = roots[0]
+
+	leaves, err := a.diff(b, func(prefix string, before, after *big.Int) {
+		diff.Subtrees = append(diff.Subtrees, SubtreeChange{Path: prefix, Height: a.depth - len(prefix), Old: before, New: after})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, leaf := range leaves {
+		diff.Keys = append(diff.Keys, KeyChange{Index: leaf.Index, Old: leaf.Local, New: leaf.Remote})
+	}
+	return diff, nil
+}
+
+// DiffReport writes a human-readable report of the changes that turn tree a
+// into tree b to w, for review before a new root is published.
+func DiffReport(a, b *SparseMerkleTree, w io.Writer) error {
+	diff, err := CompareTrees(a, b)
+	if err != nil {
+		return err
+	}
+
+	p := &reportWriter{w: w}
+	p.printf("old root: %s\n", diff.OldRoot)
+	p.printf("new root: %s\n", diff.NewRoot)
+	p.printf("\nchanged keys (%d):\n", len(diff.Keys))
+	for _, key := range diff.Keys {
+		p.printf("  %d: %s -> %s\n", key.Index, formatLeafValue(key.Old), formatLeafValue(key.New))
+	}
+	p.printf("\nchanged subtree roots (%d):\n", len(diff.Subtrees))
+	for _, subtree := range diff.Subtrees {
+		path := subtree.Path
+		if path == "" {
+			path = "(root)"
+		}
+		p.printf("  %s height %d: %s -> %s\n", path, subtree.Height, subtree.Old, subtree.New)
+	}
+	return p.err
+}
+
+// DiffReportJSON writes the changes that turn tree a into tree b to w as an
+// indented JSON StateDiff.
+func DiffReportJSON(a, b *SparseMerkleTree, w io.Writer) error {
+	diff, err := CompareTrees(a, b)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(diff)
+}
+
+// formatLeafValue formats a leaf value for a report, showing unset leaves
+// explicitly.
+func formatLeafValue(value *big.Int) string {
+	if value == nil {
+		return "<unset>"
+	}
+	return value.String()
+}
+
+// reportWriter writes formatted lines, remembering the first error.
+type reportWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *reportWriter) printf(format string, args ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
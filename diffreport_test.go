@@ -0,0 +1,50 @@
+package smt
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareTrees(t *testing.T) {
+	a, b := NewSparseMerkleTree(3, zeroLeaf), NewSparseMerkleTree(3, zeroLeaf)
+	a.Insert(1, big.NewInt(10))
+	a.Insert(6, big.NewInt(60))
+	b.Insert(1, big.NewInt(11))
+	b.Insert(6, big.NewInt(60))
+
+	diff, err := CompareTrees(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, a.root.Data, diff.OldRoot)
+	assert.Equal(t, b.root.Data, diff.NewRoot)
+	assert.Equal(t, []KeyChange{{Index: big.NewInt(1), Old: big.NewInt(10), New: big.NewInt(11)}}, diff.Keys)
+
+	var paths []string
+	for _, subtree := range diff.Subtrees {
+		paths = append(paths, subtree.Path)
+	}
+	assert.Equal(t, []string{"", "0", "00", "001"}, paths)
+
+	_, err = CompareTrees(a, NewSparseMerkleTree(4, zeroLeaf))
+	assert.Error(t, err)
+}
+
+func TestDiffReport(t *testing.T) {
+	a, b := NewSparseMerkleTree(3, zeroLeaf), NewSparseMerkleTree(3, zeroLeaf)
+	b.Insert(5, big.NewInt(7))
+
+	var text bytes.Buffer
+	assert.NoError(t, DiffReport(a, b, &text))
+	assert.Contains(t, text.String(), "changed keys (1):\n  5: <unset> -> 7\n")
+	assert.Contains(t, text.String(), "(root) height 3:")
+
+	var out bytes.Buffer
+	assert.NoError(t, DiffReportJSON(a, b, &out))
+	var diff StateDiff
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &diff))
+	assert.Equal(t, []KeyChange{{Index: big.NewInt(5), New: big.NewInt(7)}}, diff.Keys)
+	assert.Len(t, diff.Subtrees, 4)
+}
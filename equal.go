@@ -0,0 +1,72 @@
+package smt
+
+import "math/big"
+
+// treeShape is the configuration and root of a tree, read under its lock.
+type treeShape struct {
+	depth    int
+	zeroLeaf *big.Int
+	hasher   string
+	root     *big.Int
+}
+
+// shape returns the configuration and root of the tree.
+func (smt *SparseMerkleTree) shape() treeShape {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return treeShape{depth: smt.depth, zeroLeaf: smt.zeroLeaf, hasher: smt.hasher.Fingerprint(), root: smt.root.Data}
+}
+
+// SameRoot reports whether the two trees have the same root hash. It does not
+// compare their configuration; use Equal for that.
+func (smt *SparseMerkleTree) SameRoot(other *SparseMerkleTree) bool {
+	if smt == other {
+		return true
+	}
+	return smt.Root().Cmp(other.Root()) == 0
+}
+
+// Equal reports whether the two trees have the same depth, zero leaf, hasher
+// and root, and so commit to the same leaf values.
+func (smt *SparseMerkleTree) Equal(other *SparseMerkleTree) bool {
+	if smt == other {
+		return true
+	}
+	a, b := smt.shape(), other.shape()
+	return a.depth == b.depth && a.zeroLeaf.Cmp(b.zeroLeaf) == 0 && a.hasher == b.hasher && a.root.Cmp(b.root) == 0
+}
+
+// EqualLeaves reports whether the two trees are Equal and have also set the
+// same indices, so that leaves explicitly set to the zero leaf, which do not
+// change the root, are compared too.
+func (smt *SparseMerkleTree) EqualLeaves(other *SparseMerkleTree) bool {
+	if smt == other {
+		return true
+	}
+	if !smt.Equal(other) {
+		return false
+	}
+
+	// The trees are read one at a time, so that comparing a with b while
+	// another goroutine compares b with a cannot deadlock.
+	smt.mu.RLock()
+	leaves := make(map[string]*big.Int, len(smt.leaves))
+	for key, value := range smt.leaves {
+		leaves[key] = value
+	}
+	smt.mu.RUnlock()
+
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+
+	if len(leaves) != len(other.leaves) {
+		return false
+	}
+	for key, value := range other.leaves {
+		if mine, exists := leaves[key]; !exists || mine.Cmp(value) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	a := NewSparseMerkleTree(4, zeroLeaf)
+	b := NewSparseMerkleTree(4, zeroLeaf)
+	assert.True(t, a.Equal(b))
+	assert.True(t, a.EqualLeaves(b))
+
+	assert.NoError(t, a.Insert(3, big.NewInt(30)))
+	assert.False(t, a.SameRoot(b))
+	assert.False(t, a.Equal(b))
+
+	assert.NoError(t, b.Insert(3, big.NewInt(30)))
+	assert.True(t, a.SameRoot(b))
+	assert.True(t, a.Equal(b))
+	assert.True(t, a.EqualLeaves(b))
+
+	// A leaf explicitly set to the zero leaf keeps the root unchanged.
+	assert.NoError(t, b.Insert(5, zeroLeaf))
+	assert.True(t, a.Equal(b))
+	assert.False(t, a.EqualLeaves(b))
+	assert.False(t, b.EqualLeaves(a))
+}
+
+func TestEqualConfiguration(t *testing.T) {
+	a := NewSparseMerkleTree(4, zeroLeaf)
+
+	deeper := NewSparseMerkleTree(5, zeroLeaf)
+	assert.False(t, a.Equal(deeper))
+
+	otherZero := NewSparseMerkleTree(4, big.NewInt(1))
+	assert.False(t, a.Equal(otherZero))
+
+	assert.NoError(t, a.Insert(1, big.NewInt(10)))
+	clone := a.Clone()
+	assert.True(t, a.SameRoot(clone))
+	assert.True(t, a.EqualLeaves(clone))
+}
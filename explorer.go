@@ -0,0 +1,119 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	maxRecentHeads   = 32 // Number of recent commit heads a tree remembers.
+	maxOccupancyBits = 20 // Longest prefix Occupancy reports on, bounding its result to 2^20 buckets.
+)
+
+// TreeSummary describes the current state of a tree for explorers and
+// dashboards.
+type TreeSummary struct {
+	Head      TreeHead // Head of the current version.
+	LeafCount int      // Number of inserted leaves.
+	NodeCount int      // Number of stored nodes, including leaves.
+}
+
+// NodeInfo describes a single node of a tree.
+type NodeInfo struct {
+	Path      string   // Binary path of the node from the root.
+	Height    int      // Height of the node above the leaves.
+	Hash      *big.Int // Hash of the node.
+	Empty     bool     // Whether the subtree under the node holds no inserted leaves.
+	Left      *big.Int // Hash of the left child, or nil for a leaf.
+	Right     *big.Int // Hash of the right child, or nil for a leaf.
+	LeafCount int      // Number of inserted leaves under the node.
+	Key       string   // Application key of a leaf, if known.
+}
+
+// Summary returns an overview of the current state of the tree.
+func (smt *SparseMerkleTree) Summary() TreeSummary {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return TreeSummary{Head: smt.head(), LeafCount: len(smt.leaves), NodeCount: countNodes(smt.root)}
+}
+
+// RecentHeads returns the heads of the most recent commits, newest first.
+func (smt *SparseMerkleTree) RecentHeads() []TreeHead {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	heads := make([]TreeHead, len(smt.recentHeads))
+	for i, head := range smt.recentHeads {
+		heads[len(heads)-1-i] = head
+	}
+	return heads
+}
+
+// Occupancy returns the number of inserted leaves under every binary prefix
+// of the given length, indexed by the prefix value, for heatmaps of how the
+// leaves are spread over the index space.
+func (smt *SparseMerkleTree) Occupancy(prefixBits int) ([]int, error) {
+	if prefixBits < 0 || prefixBits > smt.depth || prefixBits > maxOccupancyBits {
+		return nil, fmt.Errorf("prefix length %d out of range for depth %d (at most %d)", prefixBits, smt.depth, maxOccupancyBits)
+	}
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	counts := make([]int, 1<<prefixBits)
+	for key := range smt.leaves {
+		bucket := 0
+		for _, bit := range key[:prefixBits] {
+			bucket = bucket<<1 | int(bit-'0')
+		}
+		counts[bucket]++
+	}
+	return counts, nil
+}
+
+// InspectNode describes the node at the given binary path from the root.
+func (smt *SparseMerkleTree) InspectNode(path string) (NodeInfo, error) {
+	if err := checkPrefix(path, smt.depth); err != nil {
+		return NodeInfo{}, err
+	}
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	height := smt.depth - len(path)
+	info := NodeInfo{Path: path, Height: height, Hash: smt.emptyHashes[height], Empty: true}
+	if height == 0 {
+		info.Key = smt.keyName(path)
+	}
+	for key := range smt.leaves {
+		if strings.HasPrefix(key, path) {
+			info.LeafCount++
+		}
+	}
+
+	node := smt.nodeAt(path)
+	if node == nil {
+		if height > 0 {
+			info.Left, info.Right = smt.emptyHashes[height-1], smt.emptyHashes[height-1]
+		}
+		return info, nil
+	}
+
+	info.Hash = node.Data
+	info.Empty = info.LeafCount == 0
+	if height > 0 {
+		info.Left = smt.prefixHash(path + "0")
+		info.Right = smt.prefixHash(path + "1")
+	}
+	return info, nil
+}
+
+// countNodes returns the number of nodes in the subtree rooted at node.
+func countNodes(node *MerkleNode) int {
+	if node == nil {
+		return 0
+	}
+	return 1 + countNodes(node.Left) + countNodes(node.Right)
+}
@@ -0,0 +1,59 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplorer(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(1, big.NewInt(1))
+	smt.Insert(6, big.NewInt(6))
+
+	summary := smt.Summary()
+	assert.Equal(t, 2, summary.LeafCount)
+	assert.Equal(t, 7, summary.NodeCount)
+	assert.Equal(t, 2, summary.Head.Version)
+
+	heads := smt.RecentHeads()
+	assert.Len(t, heads, 2)
+	assert.Equal(t, smt.root.Data, heads[0].Root)
+
+	occupancy, err := smt.Occupancy(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 1}, occupancy)
+	_, err = smt.Occupancy(4)
+	assert.Error(t, err)
+
+	root, err := smt.InspectNode("")
+	assert.NoError(t, err)
+	assert.Equal(t, smt.root.Data, root.Hash)
+	assert.Equal(t, 2, root.LeafCount)
+	assert.Equal(t, smt.root.Left.Data, root.Left)
+
+	empty, err := smt.InspectNode("01")
+	assert.NoError(t, err)
+	assert.True(t, empty.Empty)
+	assert.Equal(t, getHashEmptyForDepth(1, zeroLeaf), empty.Hash)
+
+	leaf, err := smt.InspectNode("110")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(6), leaf.Hash)
+	assert.Nil(t, leaf.Left)
+
+	_, err = smt.InspectNode("2")
+	assert.Error(t, err)
+}
+
+func TestRecentHeadsBounded(t *testing.T) {
+	smt := NewSparseMerkleTree(2, zeroLeaf)
+	for i := 0; i < maxRecentHeads+5; i++ {
+		smt.Insert(i%4, big.NewInt(int64(i)))
+	}
+
+	heads := smt.RecentHeads()
+	assert.Len(t, heads, maxRecentHeads)
+	assert.Equal(t, maxRecentHeads+5, heads[0].Version)
+}
@@ -0,0 +1,57 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrStaleProof is returned when a proof is bound to a tree head that is too
+// old for the verifier's freshness policy.
+var ErrStaleProof = errors.New("stale proof")
+
+// BoundClaim is a claim bound to the tree head it was generated against.
+type BoundClaim struct {
+	Head  TreeHead // Tree head whose root the claim verifies against.
+	Claim Claim    // The bound claim.
+}
+
+// Bind returns claim bound to the current tree head. The claim should have
+// been generated from the current state of the tree.
+func (smt *SparseMerkleTree) Bind(claim Claim) *BoundClaim {
+	return &BoundClaim{Head: smt.Head(), Claim: claim}
+}
+
+// FreshnessPolicy limits how old a tree head may be for proofs bound to it to
+// be accepted. Zero limits are not enforced.
+type FreshnessPolicy struct {
+	MaxVersionLag int           // Maximum number of versions the head may lag behind the latest version.
+	MaxAge        time.Duration // Maximum age of the head.
+}
+
+// Check returns ErrStaleProof if head is older than the policy allows, given
+// the latest version known to the verifier and the current time.
+func (p FreshnessPolicy) Check(head TreeHead, latestVersion int, now time.Time) error {
+	if p.MaxVersionLag > 0 && latestVersion-head.Version > p.MaxVersionLag {
+		return fmt.Errorf("%w: version %d lags latest version %d by more than %d", ErrStaleProof, head.Version, latestVersion, p.MaxVersionLag)
+	}
+	if p.MaxAge > 0 && now.Sub(head.Timestamp) > p.MaxAge {
+		return fmt.Errorf("%w: head from %s is older than %s", ErrStaleProof, head.Timestamp.Format(time.RFC3339), p.MaxAge)
+	}
+	return nil
+}
+
+// Verify checks that the bound head is fresh enough and that the claim holds
+// against its root. The head root must be one the verifier trusts for the
+// head's version, which is checked against trustedRoot. zeroLeaf is passed to
+// Verify.
+func (p FreshnessPolicy) Verify(bound *BoundClaim, trustedRoot, zeroLeaf *big.Int, latestVersion int, now time.Time) error {
+	if bound.Head.Root == nil || bound.Head.Root.Cmp(trustedRoot) != 0 {
+		return fmt.Errorf("bound head root does not match the trusted root")
+	}
+	if err := p.Check(bound.Head, latestVersion, now); err != nil {
+		return err
+	}
+	return Verify(bound.Claim, trustedRoot, zeroLeaf)
+}
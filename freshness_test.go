@@ -0,0 +1,41 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadVersion(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.Equal(t, 0, smt.Head().Version)
+
+	smt.Insert(1, big.NewInt(1))
+	_, err := smt.ApplyAtomic([]Mutation{{Index: 2, Value: big.NewInt(2)}, {Index: 3, Value: big.NewInt(3)}})
+	assert.NoError(t, err)
+
+	head := smt.Head()
+	assert.Equal(t, 2, head.Version, "a batch is a single commit")
+	assert.Equal(t, smt.root.Data, head.Root)
+}
+
+func TestFreshnessPolicy(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(1, big.NewInt(1))
+
+	claim, err := smt.MembershipClaim(1)
+	assert.NoError(t, err)
+	bound := smt.Bind(claim)
+	root := bound.Head.Root
+
+	policy := FreshnessPolicy{MaxVersionLag: 2, MaxAge: time.Minute}
+	now := bound.Head.Timestamp.Add(time.Second)
+	assert.NoError(t, policy.Verify(bound, root, zeroLeaf, bound.Head.Version+2, now))
+	assert.ErrorIs(t, policy.Verify(bound, root, zeroLeaf, bound.Head.Version+3, now), ErrStaleProof)
+	assert.ErrorIs(t, policy.Verify(bound, root, zeroLeaf, bound.Head.Version, now.Add(time.Hour)), ErrStaleProof)
+	assert.Error(t, policy.Verify(bound, zeroLeaf, zeroLeaf, bound.Head.Version, now))
+
+	assert.NoError(t, FreshnessPolicy{}.Verify(bound, root, zeroLeaf, bound.Head.Version+100, now.Add(time.Hour)))
+}
@@ -0,0 +1,186 @@
+package smt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// ErrHasherMismatch is returned when loading state recorded under a different
+// node hasher.
+var ErrHasherMismatch = errors.New("hasher mismatch")
+
+// Hasher hashes two child nodes into their parent.
+type Hasher interface {
+	Hash(left, right *big.Int) (*big.Int, error)
+	// Fingerprint identifies the hash algorithm, its field and its
+	// parameters, so that state hashed under one hasher is never reopened
+	// with another.
+	Fingerprint() string
+}
+
+// customHasher is a Hasher built from a function by NewHasher.
+type customHasher struct {
+	fingerprint string
+	hash        func(left, right *big.Int) (*big.Int, error)
+}
+
+func (h customHasher) Hash(left, right *big.Int) (*big.Int, error) { return h.hash(left, right) }
+func (h customHasher) Fingerprint() string                         { return h.fingerprint }
+
+// NewHasher returns a Hasher that hashes with hash and identifies itself with
+// fingerprint.
+func NewHasher(fingerprint string, hash func(left, right *big.Int) (*big.Int, error)) Hasher {
+	return customHasher{fingerprint: fingerprint, hash: hash}
+}
+
+// hashers holds the hashers registered with RegisterHasher, by fingerprint.
+var hashers sync.Map
+
+// RegisterHasher makes state hashed with hasher, such as snapshots of trees
+// returned by MigrateHasher, readable by ReadSnapshot and ValidateSnapshot.
+// PoseidonHasher is always registered.
+func RegisterHasher(hasher Hasher) {
+	hashers.Store(hasher.Fingerprint(), hasher)
+}
+
+// registeredHasher returns the registered hasher with the given fingerprint.
+func registeredHasher(fingerprint string) (Hasher, error) {
+	if fingerprint == PoseidonHasher.Fingerprint() {
+		return PoseidonHasher, nil
+	}
+	if hasher, ok := hashers.Load(fingerprint); ok {
+		return hasher.(Hasher), nil
+	}
+	return nil, fmt.Errorf("%w: no hasher registered for %q", ErrHasherMismatch, fingerprint)
+}
+
+// PoseidonHasher is the node hasher used by SparseMerkleTree.
+var PoseidonHasher = NewHasher(poseidonFingerprint(), func(left, right *big.Int) (*big.Int, error) {
+	return poseidon.Hash([]*big.Int{left, right})
+})
+
+// poseidonFingerprint identifies the two-input Poseidon permutation by its
+// field, width and round counts, plus a known-answer digest that changes with
+// any of its round constants or MDS matrix.
+func poseidonFingerprint() string {
+	known, err := poseidon.Hash([]*big.Int{big.NewInt(1), big.NewInt(2)})
+	if err != nil {
+		panic(err)
+	}
+	params := fmt.Sprintf("field=%s;t=3;rf=%d;rp=%d;kat=%s", constants.Q, poseidon.NROUNDSF, poseidon.NROUNDSP[1], known)
+	digest := sha256.Sum256([]byte(params))
+	return "poseidon-bn254-t3-" + hex.EncodeToString(digest[:8])
+}
+
+// EmptyRoot returns the root of a tree of the given depth in which every leaf
+// is zeroLeaf, hashing nodes with hasher. A nil hasher means PoseidonHasher,
+// for which the result is the root of a new SparseMerkleTree.
+func EmptyRoot(depth int, hasher Hasher, zeroLeaf *big.Int) (*big.Int, error) {
+	hashes, err := emptyHashesWith(depth, hasher, zeroLeaf)
+	if err != nil {
+		return nil, err
+	}
+	return hashes[depth], nil
+}
+
+// GenesisNonMembershipClaim returns a claim that the leaf at index is empty in
+// a brand-new tree of the given depth. Its siblings are empty subtree hashes,
+// so it can be produced without the tree and checked with
+// VerifyGenesisNonMembership, or with Verify against the empty root and
+// zeroLeaf when hasher is PoseidonHasher.
+func GenesisNonMembershipClaim(depth int, hasher Hasher, zeroLeaf *big.Int, index int) (*NonMembershipClaim, error) {
+	if err := checkIndex(index, depth); err != nil {
+		return nil, err
+	}
+	hashes, err := emptyHashesWith(depth, hasher, zeroLeaf)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([]*MerklePathItem, depth)
+	for i := range path {
+		path[i] = &MerklePathItem{SiblingHash: hashes[i], IsRight: (index>>i)&1 == 0}
+	}
+	return &NonMembershipClaim{Index: index, Path: path}, nil
+}
+
+// VerifyGenesisNonMembership checks that claim proves an empty leaf against
+// the empty root of a tree of the given depth in which every leaf is
+// zeroLeaf, hashing nodes with hasher.
+func VerifyGenesisNonMembership(claim *NonMembershipClaim, depth int, hasher Hasher, zeroLeaf *big.Int) error {
+	if claim == nil || zeroLeaf == nil || len(claim.Path) != depth || !pathMatchesIndex(claim.Path, claim.Index) {
+		return fmt.Errorf("malformed genesis non-membership claim")
+	}
+	if hasher == nil {
+		hasher = PoseidonHasher
+	}
+
+	emptyRoot, err := EmptyRoot(depth, hasher, zeroLeaf)
+	if err != nil {
+		return err
+	}
+	if !VerifyMerklePathWith(hasher, zeroLeaf, claim.Path, emptyRoot) {
+		return fmt.Errorf("invalid genesis non-membership claim for index %d", claim.Index)
+	}
+	return nil
+}
+
+// VerifyMerklePathWith verifies a Merkle tree path against the expected root
+// hash like VerifyMerklePath, hashing nodes with hasher, or PoseidonHasher if
+// nil.
+func VerifyMerklePathWith(hasher Hasher, leafHash *big.Int, path []*MerklePathItem, expectedRoot *big.Int) bool {
+	if hasher == nil {
+		hasher = PoseidonHasher
+	}
+	if leafHash == nil || expectedRoot == nil {
+		return false
+	}
+
+	current := leafHash
+	for _, item := range path {
+		if item == nil || item.SiblingHash == nil {
+			return false
+		}
+		left, right := current, item.SiblingHash
+		if !item.IsRight {
+			left, right = right, left
+		}
+		var err error
+		if current, err = hasher.Hash(left, right); err != nil {
+			return false
+		}
+	}
+	return current.Cmp(expectedRoot) == 0
+}
+
+// emptyHashesWith returns the hashes of empty subtrees of every height up to
+// depth, hashing nodes with hasher, or PoseidonHasher if nil.
+func emptyHashesWith(depth int, hasher Hasher, zeroLeaf *big.Int) ([]*big.Int, error) {
+	if depth < 0 {
+		return nil, fmt.Errorf("negative depth: %d", depth)
+	}
+	if zeroLeaf == nil {
+		return nil, fmt.Errorf("nil zero leaf")
+	}
+	if hasher == nil {
+		hasher = PoseidonHasher
+	}
+
+	hashes := make([]*big.Int, depth+1)
+	hashes[0] = zeroLeaf
+	for i := 1; i <= depth; i++ {
+		hash, err := hasher.Hash(hashes[i-1], hashes[i-1])
+		if err != nil {
+			return nil, fmt.Errorf("hashing empty subtree of height %d: %w", i, err)
+		}
+		hashes[i] = hash
+	}
+	return hashes, nil
+}
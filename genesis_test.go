@@ -0,0 +1,52 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyRoot(t *testing.T) {
+	root, err := EmptyRoot(8, nil, zeroLeaf)
+	assert.NoError(t, err)
+	assert.Equal(t, NewSparseMerkleTree(8, zeroLeaf).root.Data, root)
+
+	sum := NewHasher("sum", func(left, right *big.Int) (*big.Int, error) {
+		return new(big.Int).Add(left, right), nil
+	})
+	root, err = EmptyRoot(3, sum, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(8), root)
+
+	_, err = EmptyRoot(3, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestGenesisNonMembershipClaim(t *testing.T) {
+	claim, err := GenesisNonMembershipClaim(8, PoseidonHasher, zeroLeaf, 77)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyGenesisNonMembership(claim, 8, PoseidonHasher, zeroLeaf))
+	assert.Error(t, VerifyGenesisNonMembership(claim, 8, PoseidonHasher, big.NewInt(1)))
+
+	emptyRoot, _ := EmptyRoot(8, PoseidonHasher, zeroLeaf)
+	assert.NoError(t, Verify(claim, emptyRoot, zeroLeaf))
+
+	fromTree, err := NewSparseMerkleTree(8, zeroLeaf).NonMembershipClaim(77)
+	assert.NoError(t, err)
+	assert.Equal(t, fromTree, claim)
+
+	claim.Index = 78
+	assert.Error(t, VerifyGenesisNonMembership(claim, 8, PoseidonHasher, zeroLeaf))
+	assert.Error(t, VerifyGenesisNonMembership(claim, 9, PoseidonHasher, zeroLeaf))
+
+	_, err = GenesisNonMembershipClaim(8, PoseidonHasher, zeroLeaf, 256)
+	assert.Error(t, err)
+}
+
+func TestHasherFingerprint(t *testing.T) {
+	fingerprint := PoseidonHasher.Fingerprint()
+	assert.Regexp(t, `^poseidon-bn254-t3-[0-9a-f]{16}$`, fingerprint)
+	assert.Equal(t, fingerprint, NewSparseMerkleTree(2, zeroLeaf).Head().Hasher)
+	assert.Equal(t, "sum", NewHasher("sum", nil).Fingerprint())
+}
@@ -2,12 +2,14 @@ module github.com/pycckuu/smt
 
 go 1.20
 
-require github.com/iden3/go-iden3-crypto v0.0.15
+require (
+	github.com/iden3/go-iden3-crypto v0.0.15
+	github.com/stretchr/testify v1.8.4
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.8.4 // indirect
 	golang.org/x/sys v0.6.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
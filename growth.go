@@ -0,0 +1,140 @@
+package smt
+
+import (
+	"math/big"
+	"sort"
+	"time"
+)
+
+// maxCommitStats is the number of commits whose statistics a tree remembers.
+const maxCommitStats = 1 << 16
+
+// NodeHashArity is the number of inputs of every node hash: the hashes of
+// the two children.
+const NodeHashArity = 2
+
+// CommitStats describes how a commit changed the size of a tree.
+type CommitStats struct {
+	Version       int       `json:"version"`       // Version produced by the commit.
+	Timestamp     time.Time `json:"timestamp"`     // Time of the commit.
+	LeavesAdded   int       `json:"leavesAdded"`   // Number of leaves inserted at previously unset indices.
+	LeavesRemoved int       `json:"leavesRemoved"` // Number of leaves deleted.
+	LeavesUpdated int       `json:"leavesUpdated"` // Number of existing leaves given a new value.
+	NodesWritten  int       `json:"nodesWritten"`  // Number of distinct nodes rehashed on the paths of changed leaves.
+	Hashes        int       `json:"hashes"`        // Number of node hashes computed, each over NodeHashArity inputs.
+	LeafCount     int       `json:"leafCount"`     // Number of leaves after the commit.
+}
+
+// CommitStats returns the statistics of the remembered commits with versions
+// from fromVersion to toVersion inclusive, oldest first. Only the most recent
+// 65536 commits are remembered.
+func (smt *SparseMerkleTree) CommitStats(fromVersion, toVersion int) []CommitStats {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	start := sort.Search(len(smt.commitStats), func(i int) bool { return smt.commitStats[i].Version >= fromVersion })
+	end := sort.Search(len(smt.commitStats), func(i int) bool { return smt.commitStats[i].Version > toVersion })
+	if start >= end {
+		return nil
+	}
+	return append([]CommitStats(nil), smt.commitStats[start:end]...)
+}
+
+// setLeaf sets the leaf with the given binary key in the leaves map, dropping
+// the preimage, hashed key and key name recorded for the previous leaf and
+// counting the change for the next commit. Callers that record them for the
+// new leaf do so after setLeaf. The caller must hold the write lock.
+func (smt *SparseMerkleTree) setLeaf(key string, value *big.Int) {
+	if old, exists := smt.leaves[key]; exists {
+		smt.pendingStats.LeavesUpdated++
+		if old.Cmp(smt.zeroLeaf) != 0 {
+			smt.nonDefaultLeaves--
+		}
+	} else {
+		smt.pendingStats.LeavesAdded++
+	}
+	if value.Cmp(smt.zeroLeaf) != 0 {
+		smt.nonDefaultLeaves++
+	}
+	smt.markDirty(key)
+	delete(smt.preimages, key)
+	delete(smt.hashedKeys, key)
+	delete(smt.keyNames, key)
+	smt.leaves[key] = value
+}
+
+// markDirty records that the path of the leaf with the given binary key is
+// rewritten by the next commit. The caller must hold the write lock.
+func (smt *SparseMerkleTree) markDirty(key string) {
+	if smt.dirtyKeys == nil {
+		smt.dirtyKeys = make(map[string]struct{})
+	}
+	smt.dirtyKeys[key] = struct{}{}
+}
+
+// recordStats completes the statistics of the commit producing the current
+// version and starts counting the next one. The caller must hold the write
+// lock.
+func (smt *SparseMerkleTree) recordStats() {
+	keys := make([]string, 0, len(smt.dirtyKeys))
+	for key := range smt.dirtyKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Sorted keys share their nodes down to their longest common prefix.
+	stats := smt.pendingStats
+	for i, key := range keys {
+		if i == 0 {
+			stats.NodesWritten += smt.depth + 1
+			continue
+		}
+		shared := 0
+		for shared < len(key) && key[shared] == keys[i-1][shared] {
+			shared++
+		}
+		stats.NodesWritten += smt.depth - shared
+	}
+	stats.Hashes = int(smt.hashes.Swap(0))
+	stats.Version = smt.version
+	stats.Timestamp = smt.committedAt
+	stats.LeafCount = len(smt.leaves)
+
+	smt.commitStats = append(smt.commitStats, stats)
+	if len(smt.commitStats) > maxCommitStats {
+		smt.commitStats = smt.commitStats[len(smt.commitStats)-maxCommitStats:]
+	}
+	smt.pendingStats = CommitStats{}
+	smt.dirtyKeys = nil
+}
+
+// ProofStats describes the cost of the Merkle path of a leaf.
+type ProofStats struct {
+	Siblings       int `json:"siblings"`       // Number of sibling hashes in the path.
+	EmptySiblings  int `json:"emptySiblings"`  // Number of siblings that are empty subtrees, which a compact path omits.
+	VerifierHashes int `json:"verifierHashes"` // Number of node hashes a verifier computes, each over NodeHashArity inputs.
+}
+
+// ProofStats returns the cost of proving the leaf at index, set or not.
+// Generating the path reads nodes but computes no hashes.
+func (smt *SparseMerkleTree) ProofStats(index int) (ProofStats, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return ProofStats{}, err
+	}
+	return smt.proofStats(smt.generateMerklePath(smt.root, getPaddedBinaryString(index, smt.depth))), nil
+}
+
+// proofStats returns the cost of the given path of the tree. The caller must
+// hold the lock.
+func (smt *SparseMerkleTree) proofStats(path []*MerklePathItem) ProofStats {
+	stats := ProofStats{Siblings: len(path), VerifierHashes: len(path)}
+	for height, item := range path {
+		if item.SiblingHash.Cmp(smt.emptyHashes[height]) == 0 {
+			stats.EmptySiblings++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,60 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitStats(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, smt.Insert(0, big.NewInt(1)))
+	_, err := smt.BatchInsert([]LeafUpdate{{Index: 0, Value: big.NewInt(2)}, {Index: 1, Value: big.NewInt(3)}, {Index: 7, Value: big.NewInt(4)}})
+	assert.NoError(t, err)
+	assert.NoError(t, smt.Delete(1))
+
+	stats := smt.CommitStats(0, 100)
+	assert.Len(t, stats, 3)
+
+	assert.Equal(t, 1, stats[0].Version)
+	assert.Equal(t, 1, stats[0].LeavesAdded)
+	assert.Equal(t, 4, stats[0].NodesWritten, "root to leaf in a depth-3 tree")
+	assert.Equal(t, 3, stats[0].Hashes, "one per inner node on the path")
+	assert.Equal(t, 1, stats[0].LeafCount)
+
+	// Paths 000, 001 and 111 share the root, and 000 and 001 also share 0 and 00.
+	assert.Equal(t, CommitStats{Version: 2, Timestamp: stats[1].Timestamp, LeavesAdded: 2, LeavesUpdated: 1, NodesWritten: 8, Hashes: 5, LeafCount: 3}, stats[1])
+	assert.Equal(t, 1, stats[2].LeavesRemoved)
+	assert.Equal(t, 3, stats[2].Hashes, "the path of 001 keeps its sibling 000")
+	assert.Equal(t, 2, stats[2].LeafCount)
+
+	assert.Equal(t, stats[1:2], smt.CommitStats(2, 2))
+	assert.Empty(t, smt.CommitStats(4, 10))
+}
+
+func TestCommitStatsHashes(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	_, _, err := smt.SimulateBatch([]Mutation{{Index: 2, Value: big.NewInt(1)}})
+	assert.NoError(t, err)
+	_, err = smt.ApplyAtomic([]Mutation{{Index: 0, Value: big.NewInt(1)}, {Index: 1, Value: big.NewInt(2)}})
+	assert.NoError(t, err)
+	_, err = smt.ApplyParallel([]Mutation{{Index: 8, Value: big.NewInt(3)}, {Index: 15, Value: big.NewInt(4)}}, 2)
+	assert.NoError(t, err)
+
+	stats := smt.CommitStats(0, 10)
+	assert.Equal(t, 8, stats[0].Hashes, "sequential inserts rehash shared nodes, and simulations are not counted")
+	assert.Equal(t, 6, stats[1].Hashes, "a parallel batch hashes each node once")
+}
+
+func TestProofStats(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(0, big.NewInt(1)))
+	assert.NoError(t, smt.Insert(15, big.NewInt(2)))
+
+	stats, err := smt.ProofStats(1)
+	assert.NoError(t, err)
+	assert.Equal(t, ProofStats{Siblings: 4, EmptySiblings: 2, VerifierHashes: 4}, stats)
+	_, err = smt.ProofStats(16)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
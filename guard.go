@@ -0,0 +1,61 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrLeafExists is returned when inserting at an index that already holds a leaf.
+	ErrLeafExists = errors.New("leaf already exists")
+	// ErrValueMismatch is returned when a leaf does not hold the expected value.
+	ErrValueMismatch = errors.New("leaf value mismatch")
+)
+
+// InsertIfAbsent inserts value at index only if no leaf was inserted there
+// yet, and returns ErrLeafExists otherwise.
+func (smt *SparseMerkleTree) InsertIfAbsent(index int, value *big.Int) error {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return err
+	}
+
+	key := getPaddedBinaryString(index, smt.depth)
+	if _, exists := smt.leaves[key]; exists {
+		return fmt.Errorf("%w at key: %s", ErrLeafExists, key)
+	}
+	return smt.set(key, value)
+}
+
+// UpdateIfEquals replaces the leaf at index with newValue only if it currently
+// holds expected, and returns ErrValueMismatch otherwise. An index that was
+// never inserted holds the zero leaf.
+func (smt *SparseMerkleTree) UpdateIfEquals(index int, expected, newValue *big.Int) error {
+	return smt.InsertIf(index, expected, newValue)
+}
+
+// InsertIf is a compare-and-swap on the leaf at index: it inserts newValue
+// only if the leaf currently holds expectedOld, and returns ErrValueMismatch
+// otherwise, leaving the tree unchanged. An index that was never inserted
+// holds the zero leaf.
+func (smt *SparseMerkleTree) InsertIf(index int, expectedOld, newValue *big.Int) error {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return err
+	}
+	if expectedOld == nil {
+		return fmt.Errorf("nil expected value")
+	}
+
+	key := getPaddedBinaryString(index, smt.depth)
+	current := smt.leafOrZero(key)
+	if current.Cmp(expectedOld) != 0 {
+		return fmt.Errorf("%w at key %s: expected %s, got %s", ErrValueMismatch, key, expectedOld, current)
+	}
+	return smt.set(key, newValue)
+}
@@ -0,0 +1,49 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertIfAbsent(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+
+	assert.NoError(t, smt.InsertIfAbsent(2, big.NewInt(1)))
+	root := smt.root.Data
+
+	assert.ErrorIs(t, smt.InsertIfAbsent(2, big.NewInt(2)), ErrLeafExists)
+	assert.Equal(t, root, smt.root.Data)
+	assert.Equal(t, big.NewInt(1), smt.leaves[getPaddedBinaryString(2, smt.depth)])
+}
+
+func TestUpdateIfEquals(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+
+	assert.ErrorIs(t, smt.UpdateIfEquals(4, big.NewInt(1), big.NewInt(2)), ErrValueMismatch)
+	assert.NoError(t, smt.UpdateIfEquals(4, zeroLeaf, big.NewInt(2)), "unset leaves hold the zero leaf")
+
+	assert.ErrorIs(t, smt.UpdateIfEquals(4, big.NewInt(1), big.NewInt(3)), ErrValueMismatch)
+	assert.NoError(t, smt.UpdateIfEquals(4, big.NewInt(2), big.NewInt(3)))
+	assert.Equal(t, big.NewInt(3), smt.leaves[getPaddedBinaryString(4, smt.depth)])
+}
+
+func TestInsertIf(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+
+	assert.NoError(t, smt.InsertIf(1, zeroLeaf, big.NewInt(5)))
+	version := smt.Head().Version
+
+	err := smt.InsertIf(1, zeroLeaf, big.NewInt(6))
+	assert.ErrorIs(t, err, ErrValueMismatch)
+	assert.Equal(t, version, smt.Head().Version, "a conflict leaves the tree unchanged")
+
+	assert.NoError(t, smt.InsertIf(1, big.NewInt(5), big.NewInt(6)))
+	value, _ := smt.Get(1)
+	assert.Equal(t, big.NewInt(6), value)
+
+	assert.ErrorIs(t, smt.InsertIf(8, zeroLeaf, big.NewInt(1)), ErrIndexOutOfRange)
+	assert.Error(t, smt.InsertIf(1, nil, big.NewInt(1)))
+	assert.Error(t, smt.InsertIf(1, big.NewInt(6), nil))
+}
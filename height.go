@@ -0,0 +1,109 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// ErrUnknownHeight is returned when no commit was tagged at or below a
+// requested height.
+var ErrUnknownHeight = errors.New("unknown height")
+
+// heightTag is a commit tagged with an external height, such as a block
+// number, together with the root node of that version.
+type heightTag struct {
+	height uint64      // External height of the tag.
+	head   TreeHead    // Head of the tagged version.
+	root   *MerkleNode // Root node of the tagged version.
+}
+
+// TagHeight tags the current version of the tree with an external height,
+// such as the block number it was committed at, so it can later be queried
+// with RootAtHeight and ProveAtHeight. Heights must increase from tag to tag.
+// Tagging copies no nodes: commits copy the nodes they change, so a tag only
+// keeps alive the nodes later commits replaced. Old tags can be released
+// with PruneHeights.
+func (smt *SparseMerkleTree) TagHeight(height uint64) error {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if n := len(smt.heights); n > 0 && height <= smt.heights[n-1].height {
+		return fmt.Errorf("height %d is not above the last tagged height %d", height, smt.heights[n-1].height)
+	}
+	smt.heights = append(smt.heights, heightTag{height: height, head: smt.head(), root: smt.root})
+	return nil
+}
+
+// HeadAtHeight returns the head of the tree at an external height: the head
+// of the version tagged with the highest height not above it.
+func (smt *SparseMerkleTree) HeadAtHeight(height uint64) (TreeHead, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	tag, err := smt.tagAt(height)
+	if err != nil {
+		return TreeHead{}, err
+	}
+	return tag.head, nil
+}
+
+// RootAtHeight returns the root of the tree at an external height.
+func (smt *SparseMerkleTree) RootAtHeight(height uint64) (*big.Int, error) {
+	head, err := smt.HeadAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return head.Root, nil
+}
+
+// ProveAtHeight returns a claim of the value the leaf at index held at an
+// external height, the zero leaf if it was unset. The claim verifies against
+// RootAtHeight(height).
+func (smt *SparseMerkleTree) ProveAtHeight(index int, height uint64) (*MembershipClaim, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	tag, err := smt.tagAt(height)
+	if err != nil {
+		return nil, err
+	}
+
+	key := getPaddedBinaryString(index, smt.depth)
+	return &MembershipClaim{Index: index, Leaf: copyInt(smt.leafIn(tag.root, key)), Path: smt.generateMerklePath(tag.root, key)}, nil
+}
+
+// PruneHeights releases the tags below height, keeping the last of them so
+// that heights in between still resolve.
+func (smt *SparseMerkleTree) PruneHeights(height uint64) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	i := sort.Search(len(smt.heights), func(i int) bool { return smt.heights[i].height > height })
+	if i > 1 {
+		smt.heights = append([]heightTag(nil), smt.heights[i-1:]...)
+	}
+}
+
+// tagAt returns the tag with the highest height not above height. The caller
+// must hold the lock.
+func (smt *SparseMerkleTree) tagAt(height uint64) (heightTag, error) {
+	i := sort.Search(len(smt.heights), func(i int) bool { return smt.heights[i].height > height })
+	if i == 0 {
+		return heightTag{}, fmt.Errorf("%w: %d", ErrUnknownHeight, height)
+	}
+	return smt.heights[i-1], nil
+}
+
+// leafIn returns the leaf with the given binary key in the tree rooted at
+// root, or the zero leaf if it is unset.
+func (smt *SparseMerkleTree) leafIn(root *MerkleNode, key string) *big.Int {
+	if node := smt.nodeIn(root, key); node != nil {
+		return node.Data
+	}
+	return smt.zeroLeaf
+}
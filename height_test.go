@@ -0,0 +1,87 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeights(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	_, err := smt.RootAtHeight(100)
+	assert.ErrorIs(t, err, ErrUnknownHeight)
+
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	assert.NoError(t, smt.TagHeight(100))
+	rootAt100 := smt.Root()
+
+	assert.NoError(t, smt.Insert(3, big.NewInt(31)))
+	assert.NoError(t, smt.Insert(5, big.NewInt(50)))
+	assert.NoError(t, smt.TagHeight(105))
+	assert.Error(t, smt.TagHeight(105), "heights must increase")
+
+	root, err := smt.RootAtHeight(100)
+	assert.NoError(t, err)
+	assert.Equal(t, rootAt100, root)
+	root, err = smt.RootAtHeight(104)
+	assert.NoError(t, err)
+	assert.Equal(t, rootAt100, root, "untagged heights resolve to the last tag below")
+	head, err := smt.HeadAtHeight(200)
+	assert.NoError(t, err)
+	assert.Equal(t, smt.Head().Version, head.Version)
+	_, err = smt.RootAtHeight(99)
+	assert.ErrorIs(t, err, ErrUnknownHeight)
+
+	claim, err := smt.ProveAtHeight(3, 102)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(30), claim.Leaf)
+	assert.NoError(t, Verify(claim, rootAt100, zeroLeaf))
+
+	unset, err := smt.ProveAtHeight(5, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, zeroLeaf, unset.Leaf)
+	assert.NoError(t, Verify(unset, rootAt100, zeroLeaf))
+
+	current, err := smt.ProveAtHeight(5, 105)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(50), current.Leaf)
+	assert.NoError(t, Verify(current, smt.Root(), zeroLeaf))
+}
+
+func TestHeightsShareSubtrees(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	for i := 0; i < 256; i += 17 {
+		assert.NoError(t, smt.Insert(i, big.NewInt(int64(i))))
+	}
+	assert.NoError(t, smt.TagHeight(1))
+	rootAt1 := smt.Root()
+
+	assert.NoError(t, smt.Insert(0, big.NewInt(1000)))
+	assert.NoError(t, smt.TagHeight(2))
+
+	first, second := smt.heights[0].root, smt.heights[1].root
+	assert.NotSame(t, first, second)
+	assert.Same(t, first.Right, second.Right, "subtrees the commit did not touch are shared")
+	assert.Equal(t, rootAt1, first.Data, "the tagged version is not modified")
+
+	claim, err := smt.ProveAtHeight(0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), claim.Leaf)
+	assert.NoError(t, Verify(claim, rootAt1, zeroLeaf))
+}
+
+func TestPruneHeights(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	for height := uint64(1); height <= 5; height++ {
+		assert.NoError(t, smt.Insert(int(height), big.NewInt(int64(height))))
+		assert.NoError(t, smt.TagHeight(height*10))
+	}
+
+	smt.PruneHeights(35)
+	_, err := smt.RootAtHeight(25)
+	assert.ErrorIs(t, err, ErrUnknownHeight)
+	_, err = smt.RootAtHeight(35)
+	assert.NoError(t, err, "the last tag below the pruning height is kept")
+	assert.Len(t, smt.heights, 3)
+}
@@ -1,11 +1,16 @@
 package smt
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 	"strconv"
+	"strings"
 
+	"github.com/iden3/go-iden3-crypto/constants"
 	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/iden3/go-iden3-crypto/utils"
+	"github.com/pycckuu/smt/verify"
 )
 
 // getHashEmptyForDepth calculates the hash value for an empty node at a given depth.
@@ -17,6 +22,17 @@ func getHashEmptyForDepth(depth int, zeroLeaf *big.Int) *big.Int {
 	return h
 }
 
+// getEmptyHashes returns the hashes of empty subtrees of every height from 0
+// (the zero leaf) up to depth.
+func getEmptyHashes(depth int, zeroLeaf *big.Int) []*big.Int {
+	hashes := make([]*big.Int, depth+1)
+	hashes[0] = zeroLeaf
+	for i := 1; i <= depth; i++ {
+		hashes[i], _ = poseidon.Hash([]*big.Int{hashes[i-1], hashes[i-1]})
+	}
+	return hashes
+}
+
 // hashChildren computes the hash value of two child nodes.
 func hashChildren(left, right *MerkleNode, depth int, zeroLeaf *big.Int) *big.Int {
 	leftData := getHashEmptyForDepth(depth-1, zeroLeaf)
@@ -36,19 +52,18 @@ func hashChildren(left, right *MerkleNode, depth int, zeroLeaf *big.Int) *big.In
 
 // getPathBit retrieves the bit value of the key at the specified depth.
 func getPathBit(key string, depth int) int {
-	if len(key) == 0 {
+	if len(key) == 0 || key[depth] != '1' {
 		return 0
 	}
-	i, _ := strconv.Atoi(key[depth : depth+1])
-	return i
+	return 1
 }
 
 // getPaddedBinaryString returns a binary string representation of an integer,
 // padded with leading zeros to a specified length.
 func getPaddedBinaryString(i int, depth int) string {
 	binStr := strconv.FormatInt(int64(i), 2)
-	for len(binStr) < depth {
-		binStr = "0" + binStr
+	if len(binStr) < depth {
+		binStr = strings.Repeat("0", depth-len(binStr)) + binStr
 	}
 	return binStr
 }
@@ -64,3 +79,83 @@ func NewDeterministicSparseMerkleTree(depth int, zeroLeaf *big.Int) *SparseMerkl
 
 	return smt
 }
+
+// pathMatchesIndex reports whether the sibling positions of a leaf-to-root
+// path are those of the leaf at the given index.
+func pathMatchesIndex(path []*MerklePathItem, index int) bool {
+	return verify.PathMatchesIndex(path, index)
+}
+
+// getIndexFromBinaryString converts a padded binary key back to its leaf
+// index.
+func getIndexFromBinaryString(key string) (int, error) {
+	index, err := strconv.ParseInt(key, 2, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid leaf key %q: %w", key, err)
+	}
+	return int(index), nil
+}
+
+// checkIndex returns an error if index does not address a leaf of a tree with
+// the given depth.
+func checkIndex(index int, depth int) error {
+	if index < 0 || (depth < 63 && index >= 1<<depth) {
+		return fmt.Errorf("%w: index %d for depth %d", ErrIndexOutOfRange, index, depth)
+	}
+	return nil
+}
+
+// checkKey returns an error if key is not the binary key of a leaf of a tree
+// with the given depth.
+func checkKey(key string, depth int) error {
+	if len(key) != depth || strings.Trim(key, "01") != "" {
+		return fmt.Errorf("%w: key %q for depth %d", ErrIndexOutOfRange, key, depth)
+	}
+	return nil
+}
+
+// checkPrefix returns an error if prefix is not the binary path of a node of a
+// tree with the given depth.
+func checkPrefix(prefix string, depth int) error {
+	if len(prefix) > depth || strings.Trim(prefix, "01") != "" {
+		return fmt.Errorf("invalid node path %q for depth %d", prefix, depth)
+	}
+	return nil
+}
+
+// copyInt returns a copy of value, or nil if value is nil. The tree copies
+// every value it stores or returns, so that callers cannot change its state
+// behind its root.
+func copyInt(value *big.Int) *big.Int {
+	if value == nil {
+		return nil
+	}
+	return new(big.Int).Set(value)
+}
+
+// checkValue returns an error wrapping ErrInvalidValue if value is not a
+// canonical element of the BN254 scalar field. Poseidon would silently reduce
+// such a value, producing roots that circuits cannot reproduce.
+func checkValue(value *big.Int) error {
+	if value == nil {
+		return fmt.Errorf("%w: nil leaf value", ErrInvalidValue)
+	}
+	if value.Sign() < 0 {
+		return fmt.Errorf("%w: leaf value %s is negative", ErrInvalidValue, value)
+	}
+	if !utils.CheckBigIntInField(value) {
+		return fmt.Errorf("%w: leaf value %s is not below the field order %s", ErrInvalidValue, value, constants.Q)
+	}
+	return nil
+}
+
+// getBinaryStringFromBytes returns the binary string representation of a
+// byte slice, most significant bit first.
+func getBinaryStringFromBytes(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b) * 8)
+	for _, c := range b {
+		sb.WriteString(fmt.Sprintf("%08b", c))
+	}
+	return sb.String()
+}
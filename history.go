@@ -0,0 +1,70 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RootHistory is an append-only Merkle tree over historical state roots.
+// Its root (the meta-root) commits to every state root appended so far, so a
+// client that only trusts the latest meta-root can verify that a given root
+// was the state at a given version.
+type RootHistory struct {
+	tree  *SparseMerkleTree // Auxiliary tree whose leaf at index v is the state root of version v.
+	roots []*big.Int        // State roots in the order they were appended.
+}
+
+// NewRootHistory creates an empty root history able to hold 2^depth roots.
+func NewRootHistory(depth int, zeroLeaf *big.Int) *RootHistory {
+	return &RootHistory{tree: NewSparseMerkleTree(depth, zeroLeaf)}
+}
+
+// Append records root as the next version and returns that version.
+func (h *RootHistory) Append(root *big.Int) (int, error) {
+	version := len(h.roots)
+	if version >= 1<<h.tree.depth {
+		return 0, fmt.Errorf("root history is full: capacity %d", 1<<h.tree.depth)
+	}
+
+	if err := h.tree.Insert(version, root); err != nil {
+		return 0, err
+	}
+	h.roots = append(h.roots, root)
+	return version, nil
+}
+
+// Len returns the number of roots recorded in the history.
+func (h *RootHistory) Len() int {
+	return len(h.roots)
+}
+
+// MetaRoot returns the root of the history tree.
+func (h *RootHistory) MetaRoot() *big.Int {
+	return h.tree.root.Data
+}
+
+// RootAt returns the state root recorded for the given version.
+func (h *RootHistory) RootAt(version int) (*big.Int, error) {
+	if version < 0 || version >= len(h.roots) {
+		return nil, fmt.Errorf("no root recorded for version: %d", version)
+	}
+	return h.roots[version], nil
+}
+
+// ProveRoot generates a Merkle path showing that the root recorded for the
+// given version is committed to by the current meta-root.
+func (h *RootHistory) ProveRoot(version int) ([]*MerklePathItem, error) {
+	if version < 0 || version >= len(h.roots) {
+		return nil, fmt.Errorf("no root recorded for version: %d", version)
+	}
+	return h.tree.GenerateMerklePath(version)
+}
+
+// VerifyRootAtVersion verifies that root was the state at the given version
+// according to the history committed to by metaRoot.
+func VerifyRootAtVersion(root *big.Int, version int, path []*MerklePathItem, metaRoot *big.Int) bool {
+	if !pathMatchesIndex(path, version) {
+		return false
+	}
+	return VerifyMerklePath(root, path, metaRoot)
+}
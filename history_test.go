@@ -0,0 +1,49 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootHistory(t *testing.T) {
+	history := NewRootHistory(3, zeroLeaf)
+	tree := NewSparseMerkleTree(2, zeroLeaf)
+
+	var roots []*big.Int
+	for i := 0; i < 4; i++ {
+		tree.Insert(i, big.NewInt(int64(i+1)))
+		version, err := history.Append(tree.root.Data)
+		assert.NoError(t, err)
+		assert.Equal(t, i, version)
+		roots = append(roots, tree.root.Data)
+	}
+	assert.Equal(t, 4, history.Len())
+
+	metaRoot := history.MetaRoot()
+	for version, root := range roots {
+		recorded, err := history.RootAt(version)
+		assert.NoError(t, err)
+		assert.Equal(t, root, recorded)
+
+		path, err := history.ProveRoot(version)
+		assert.NoError(t, err)
+		assert.True(t, VerifyRootAtVersion(root, version, path, metaRoot))
+		assert.False(t, VerifyRootAtVersion(root, (version+1)%len(roots), path, metaRoot), "path must be bound to its version")
+	}
+
+	_, err := history.ProveRoot(4)
+	assert.Error(t, err)
+}
+
+func TestRootHistoryFull(t *testing.T) {
+	history := NewRootHistory(1, zeroLeaf)
+
+	_, err := history.Append(big.NewInt(1))
+	assert.NoError(t, err)
+	_, err = history.Append(big.NewInt(2))
+	assert.NoError(t, err)
+	_, err = history.Append(big.NewInt(3))
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package smt
+
+import "math/big"
+
+// Tree is the read and write surface of a sparse Merkle tree, implemented by
+// SparseMerkleTree and by the fakes in package smtmock.
+type Tree interface {
+	Insert(index int, value *big.Int) error
+	Delete(index int) error
+	Leaf(index int) (*big.Int, error)
+	Has(index int) bool
+	Len() int
+	Root() *big.Int
+	Head() TreeHead
+}
+
+// Prover generates claims and bundles against the current state of a tree.
+type Prover interface {
+	MembershipClaim(index int) (*MembershipClaim, error)
+	NonMembershipClaim(index int) (*NonMembershipClaim, error)
+	NewBundle(members, nonMembers []int) (*Bundle, error)
+}
+
+// Verifier checks a claim against a trusted root and zero leaf.
+type Verifier interface {
+	Verify(claim Claim, trustedRoot, zeroLeaf *big.Int) error
+}
+
+// VerifierFunc adapts a function to a Verifier.
+type VerifierFunc func(claim Claim, trustedRoot, zeroLeaf *big.Int) error
+
+// Verify implements Verifier.
+func (f VerifierFunc) Verify(claim Claim, trustedRoot, zeroLeaf *big.Int) error {
+	return f(claim, trustedRoot, zeroLeaf)
+}
+
+// ClaimVerifier is the Verifier backed by Verify.
+var ClaimVerifier Verifier = VerifierFunc(Verify)
@@ -0,0 +1,20 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterfaces(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	var tree Tree = smt
+	var prover Prover = smt
+
+	assert.NoError(t, tree.Insert(3, big.NewInt(30)))
+	claim, err := prover.MembershipClaim(3)
+	assert.NoError(t, err)
+	assert.NoError(t, ClaimVerifier.Verify(claim, tree.Root(), zeroLeaf))
+	assert.Error(t, ClaimVerifier.Verify(claim, zeroLeaf, zeroLeaf))
+}
@@ -0,0 +1,140 @@
+package smt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// CelestiaProof is a membership or non-membership proof produced by
+// celestiaorg/smt with its default SHA-256 hasher.
+type CelestiaProof struct {
+	SideNodes             [][]byte // Sibling hashes, from the leaf up to the root.
+	NonMembershipLeafData []byte   // Leaf found at the key's position in a non-membership proof, or nil.
+}
+
+// Hashing conventions of celestiaorg/smt.
+var (
+	celestiaLeafPrefix = []byte{0}
+	celestiaNodePrefix = []byte{1}
+)
+
+// VerifyCelestiaProof verifies a celestiaorg/smt proof that key holds value
+// under root. An empty value verifies a non-membership proof. Keys are hashed
+// into 256-bit paths, leaves hash as SHA-256(0x00 || path || SHA-256(value)),
+// inner nodes as SHA-256(0x01 || left || right), and empty subtrees are 32
+// zero bytes.
+func VerifyCelestiaProof(proof CelestiaProof, root, key, value []byte) bool {
+	const hashSize = sha256.Size
+	if len(proof.SideNodes) > hashSize*8 {
+		return false
+	}
+	for _, node := range proof.SideNodes {
+		if len(node) != hashSize {
+			return false
+		}
+	}
+
+	path := celestiaDigest(key)
+	var current []byte
+	if len(value) == 0 {
+		if proof.NonMembershipLeafData == nil {
+			current = make([]byte, hashSize)
+		} else {
+			data := proof.NonMembershipLeafData
+			if len(data) != len(celestiaLeafPrefix)+2*hashSize || !bytes.HasPrefix(data, celestiaLeafPrefix) {
+				return false
+			}
+			if bytes.Equal(data[len(celestiaLeafPrefix):len(celestiaLeafPrefix)+hashSize], path) {
+				return false
+			}
+			current = celestiaDigest(data)
+		}
+	} else {
+		current = celestiaDigest(celestiaLeafPrefix, path, celestiaDigest(value))
+	}
+
+	for i, node := range proof.SideNodes {
+		position := len(proof.SideNodes) - 1 - i
+		if path[position/8]&(1<<(7-position%8)) != 0 {
+			current = celestiaDigest(celestiaNodePrefix, node, current)
+		} else {
+			current = celestiaDigest(celestiaNodePrefix, current, node)
+		}
+	}
+
+	return bytes.Equal(current, root)
+}
+
+// celestiaDigest returns the SHA-256 hash of the concatenation of parts.
+func celestiaDigest(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// Iden3Proof is a membership or non-membership proof produced by iden3
+// go-merkletree (and circomlib's smt), which share the same layout.
+type Iden3Proof struct {
+	Existence bool       // Whether the proof shows the key exists.
+	Siblings  []*big.Int // All siblings from the root down, with 0 for empty subtrees.
+	AuxKey    *big.Int   // Key of the leaf found at the key's position in a non-membership proof, or nil.
+	AuxValue  *big.Int   // Value of that leaf.
+}
+
+// VerifyIden3Proof verifies an iden3 go-merkletree proof for key under root.
+// For a membership proof, value is the leaf value; it is ignored otherwise.
+// Leaves hash as Poseidon(key, value, 1), inner nodes as Poseidon(left,
+// right), empty subtrees are 0, and the path follows the key's bits starting
+// from the least significant.
+func VerifyIden3Proof(proof *Iden3Proof, root, key, value *big.Int) bool {
+	if proof == nil || root == nil || key == nil {
+		return false
+	}
+	if !utils.CheckBigIntInField(key) || len(proof.Siblings) > 254 {
+		return false
+	}
+	for _, sibling := range proof.Siblings {
+		if sibling == nil || !utils.CheckBigIntInField(sibling) {
+			return false
+		}
+	}
+
+	var current *big.Int
+	var err error
+	switch {
+	case proof.Existence:
+		if value == nil || !utils.CheckBigIntInField(value) {
+			return false
+		}
+		current, err = poseidon.Hash([]*big.Int{key, value, big.NewInt(1)})
+	case proof.AuxKey == nil:
+		current = new(big.Int)
+	default:
+		if proof.AuxValue == nil || proof.AuxKey.Cmp(key) == 0 {
+			return false
+		}
+		current, err = poseidon.Hash([]*big.Int{proof.AuxKey, proof.AuxValue, big.NewInt(1)})
+	}
+	if err != nil {
+		return false
+	}
+
+	for level := len(proof.Siblings) - 1; level >= 0; level-- {
+		if key.Bit(level) == 1 {
+			current, err = poseidon.Hash([]*big.Int{proof.Siblings[level], current})
+		} else {
+			current, err = poseidon.Hash([]*big.Int{current, proof.Siblings[level]})
+		}
+		if err != nil {
+			return false
+		}
+	}
+
+	return current.Cmp(root) == 0
+}
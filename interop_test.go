@@ -0,0 +1,67 @@
+package smt
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyCelestiaProof(t *testing.T) {
+	leaf := func(key, value string) []byte {
+		path := sha256.Sum256([]byte(key))
+		valueHash := sha256.Sum256([]byte(value))
+		return celestiaDigest([]byte{0}, path[:], valueHash[:])
+	}
+
+	// "a" and "b" hash to paths whose first bits are 1 and 0 respectively,
+	// so a two-leaf tree holds them directly under the root.
+	leafA, leafB := leaf("a", "1"), leaf("b", "2")
+	root := celestiaDigest([]byte{1}, leafB, leafA)
+
+	assert.True(t, VerifyCelestiaProof(CelestiaProof{SideNodes: [][]byte{leafB}}, root, []byte("a"), []byte("1")))
+	assert.True(t, VerifyCelestiaProof(CelestiaProof{SideNodes: [][]byte{leafA}}, root, []byte("b"), []byte("2")))
+	assert.False(t, VerifyCelestiaProof(CelestiaProof{SideNodes: [][]byte{leafB}}, root, []byte("a"), []byte("2")))
+
+	// "y" shares the first bit with "a", so its position holds leaf "a".
+	pathA := sha256.Sum256([]byte("a"))
+	valueA := sha256.Sum256([]byte("1"))
+	nonMembership := CelestiaProof{
+		SideNodes:             [][]byte{leafB},
+		NonMembershipLeafData: append(append([]byte{0}, pathA[:]...), valueA[:]...),
+	}
+	assert.True(t, VerifyCelestiaProof(nonMembership, root, []byte("y"), nil))
+	assert.False(t, VerifyCelestiaProof(nonMembership, root, []byte("a"), nil))
+	assert.False(t, VerifyCelestiaProof(CelestiaProof{SideNodes: [][]byte{{1}}}, root, []byte("a"), []byte("1")))
+}
+
+func TestVerifyIden3Proof(t *testing.T) {
+	leaf := func(key, value int64) *big.Int {
+		h, _ := poseidon.Hash([]*big.Int{big.NewInt(key), big.NewInt(value), big.NewInt(1)})
+		return h
+	}
+	node := func(left, right *big.Int) *big.Int {
+		h, _ := poseidon.Hash([]*big.Int{left, right})
+		return h
+	}
+
+	// Keys 1 (bits 1,0) and 3 (bits 1,1) both go right at the root and split
+	// one level below, leaving the root's left subtree empty.
+	zero := new(big.Int)
+	inner := node(leaf(1, 10), leaf(3, 30))
+	root := node(zero, inner)
+
+	membership := &Iden3Proof{Existence: true, Siblings: []*big.Int{zero, leaf(3, 30)}}
+	assert.True(t, VerifyIden3Proof(membership, root, big.NewInt(1), big.NewInt(10)))
+	assert.False(t, VerifyIden3Proof(membership, root, big.NewInt(1), big.NewInt(11)))
+
+	empty := &Iden3Proof{Siblings: []*big.Int{inner}}
+	assert.True(t, VerifyIden3Proof(empty, root, big.NewInt(2), nil))
+
+	// Key 7 (bits 1,1,1) ends at leaf 3's position.
+	aux := &Iden3Proof{Siblings: []*big.Int{zero, leaf(1, 10)}, AuxKey: big.NewInt(3), AuxValue: big.NewInt(30)}
+	assert.True(t, VerifyIden3Proof(aux, root, big.NewInt(7), nil))
+	assert.False(t, VerifyIden3Proof(aux, root, big.NewInt(3), nil))
+}
@@ -0,0 +1,169 @@
+package smt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// KeyCodec translates application keys, such as names or composite tuples
+// encoded with TupleKey, to leaf indices of a tree with the given depth.
+type KeyCodec interface {
+	// EncodeKey returns the index of the leaf for key.
+	EncodeKey(key string, depth int) (int, error)
+	// DecodeKey returns the key of the leaf at index, or false if the codec
+	// cannot invert its encoding. Keys of non-invertible codecs are recovered
+	// from the names a tree records on InsertKey.
+	DecodeKey(index int, depth int) (string, bool)
+}
+
+// HashedKeyCodec maps keys to indices as DeriveIndex does in its domain. It
+// cannot decode indices.
+type HashedKeyCodec struct {
+	Domain KeyDomain // Domain separating these keys from other derived indices.
+}
+
+// EncodeKey implements KeyCodec.
+func (c HashedKeyCodec) EncodeKey(key string, depth int) (int, error) {
+	return deriveIndex(c.Domain, []byte(key), depth), nil
+}
+
+// DecodeKey implements KeyCodec.
+func (HashedKeyCodec) DecodeKey(int, int) (string, bool) {
+	return "", false
+}
+
+// DecimalKeyCodec maps keys that are decimal leaf indices to themselves.
+type DecimalKeyCodec struct{}
+
+// EncodeKey implements KeyCodec.
+func (DecimalKeyCodec) EncodeKey(key string, depth int) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("invalid decimal key %q: %w", key, err)
+	}
+	if err := checkIndex(index, depth); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// DecodeKey implements KeyCodec.
+func (DecimalKeyCodec) DecodeKey(index int, depth int) (string, bool) {
+	if checkIndex(index, depth) != nil {
+		return "", false
+	}
+	return strconv.Itoa(index), true
+}
+
+// TupleKey encodes the parts of a composite key as a single unambiguous key,
+// a JSON array of strings.
+func TupleKey(parts ...string) string {
+	if parts == nil {
+		parts = []string{}
+	}
+	data, _ := json.Marshal(parts)
+	return string(data)
+}
+
+// SetKeyCodec sets the codec used by InsertKey and KeyIndex. Without one the
+// tree uses HashedKeyCodec in KeyDomainBytes. It returns ErrConfigFrozen once
+// the tree holds leaves, since their keys would map to other indices.
+func (smt *SparseMerkleTree) SetKeyCodec(codec KeyCodec) error {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := smt.checkUnfrozen("key codec"); err != nil {
+		return err
+	}
+	smt.keyCodec = codec
+	return nil
+}
+
+// KeyIndex returns the index of the leaf for key under the tree's key codec.
+func (smt *SparseMerkleTree) KeyIndex(key string) (int, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return smt.keyIndex(key)
+}
+
+// keyIndex implements KeyIndex. The caller must hold the lock.
+func (smt *SparseMerkleTree) keyIndex(key string) (int, error) {
+	codec := smt.keyCodec
+	if codec == nil {
+		codec = HashedKeyCodec{Domain: KeyDomainBytes}
+	}
+	index, err := codec.EncodeKey(key, smt.depth)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkIndex(index, smt.depth); err != nil {
+		return 0, fmt.Errorf("key codec returned invalid index for %q: %w", key, err)
+	}
+	return index, nil
+}
+
+// InsertKey inserts value at the index of key and records key as the name of
+// the leaf, so that exports, explorers and proofs can show it. Inserting a
+// different key that maps to the same index fails with ErrKeyCollision.
+func (smt *SparseMerkleTree) InsertKey(key string, value *big.Int) (int, error) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	index, err := smt.keyIndex(key)
+	if err != nil {
+		return 0, err
+	}
+
+	binaryKey := getPaddedBinaryString(index, smt.depth)
+	if name, named := smt.keyNames[binaryKey]; named && name != key {
+		return 0, fmt.Errorf("%w: %q and %q both map to index %d", ErrKeyCollision, name, key, index)
+	}
+
+	if err := smt.set(binaryKey, value); err != nil {
+		return 0, err
+	}
+	smt.setKeyName(binaryKey, key)
+	return index, nil
+}
+
+// KeyName returns the application key of the leaf at index, decoded by the
+// tree's key codec or recorded by InsertKey.
+func (smt *SparseMerkleTree) KeyName(index int) (string, bool) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if checkIndex(index, smt.depth) != nil {
+		return "", false
+	}
+	name := smt.keyName(getPaddedBinaryString(index, smt.depth))
+	return name, name != ""
+}
+
+// keyName returns the application key of the leaf with the given binary key,
+// or "" if it has none. The caller must hold the lock.
+func (smt *SparseMerkleTree) keyName(binaryKey string) string {
+	if name, named := smt.keyNames[binaryKey]; named {
+		return name
+	}
+	if smt.keyCodec == nil {
+		return ""
+	}
+	index, err := getIndexFromBinaryString(binaryKey)
+	if err != nil {
+		return ""
+	}
+	name, _ := smt.keyCodec.DecodeKey(index, smt.depth)
+	return name
+}
+
+// setKeyName records name as the application key of the leaf with the given
+// binary key. The caller must hold the write lock.
+func (smt *SparseMerkleTree) setKeyName(binaryKey, name string) {
+	if smt.keyNames == nil {
+		smt.keyNames = make(map[string]string)
+	}
+	smt.keyNames[binaryKey] = name
+}
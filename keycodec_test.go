@@ -0,0 +1,77 @@
+package smt
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertKey(t *testing.T) {
+	smt := NewSparseMerkleTree(16, zeroLeaf)
+	key := TupleKey("accounts", "alice")
+
+	index, err := smt.InsertKey(key, big.NewInt(7))
+	assert.NoError(t, err)
+	assert.Equal(t, smt.DeriveIndex(KeyDomainBytes, []byte(key)), index)
+
+	name, ok := smt.KeyName(index)
+	assert.True(t, ok)
+	assert.Equal(t, `["accounts","alice"]`, name)
+
+	info, err := smt.InspectNode(getPaddedBinaryString(index, 16))
+	assert.NoError(t, err)
+	assert.Equal(t, key, info.Key)
+
+	bundle, err := smt.NewBundle([]int{index}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, key, bundle.Inclusions[0].Key)
+
+	var buf bytes.Buffer
+	assert.NoError(t, smt.WriteSnapshot(&buf))
+	restored, err := ReadSnapshot(&buf)
+	assert.NoError(t, err)
+	name, _ = restored.KeyName(index)
+	assert.Equal(t, key, name)
+
+	assert.NoError(t, smt.Delete(index))
+	_, ok = smt.KeyName(index)
+	assert.False(t, ok)
+}
+
+func TestInsertKeyCollision(t *testing.T) {
+	smt := NewSparseMerkleTree(2, zeroLeaf)
+	first, err := smt.InsertKey("a", big.NewInt(1))
+	assert.NoError(t, err)
+
+	// A depth-2 tree has four indices, so some other key must collide.
+	for i := 0; i < 64; i++ {
+		other := TupleKey("b", string(rune('a'+i)))
+		if index, _ := smt.KeyIndex(other); index == first {
+			_, err = smt.InsertKey(other, big.NewInt(2))
+			assert.ErrorIs(t, err, ErrKeyCollision)
+			return
+		}
+	}
+	t.Fatal("no colliding key found")
+}
+
+func TestDecimalKeyCodec(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	assert.NoError(t, smt.SetKeyCodec(DecimalKeyCodec{}))
+
+	index, err := smt.InsertKey("42", big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 42, index)
+
+	assert.NoError(t, smt.Insert(7, big.NewInt(1)))
+	name, ok := smt.KeyName(7)
+	assert.True(t, ok, "invertible codecs name every leaf")
+	assert.Equal(t, "7", name)
+
+	_, err = smt.InsertKey("256", big.NewInt(1))
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	_, err = smt.InsertKey("alice", big.NewInt(1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// KeyHistoryEntry proves the value a leaf held at one version of the tree.
+type KeyHistoryEntry struct {
+	Version     int               `json:"version"`     // Version of the tree.
+	Root        *big.Int          `json:"root"`        // Root of the tree at Version.
+	Leaf        *big.Int          `json:"leaf"`        // Value of the leaf at Version, the zero leaf if it was unset.
+	Path        []*MerklePathItem `json:"path"`        // Merkle path from the leaf to Root.
+	HistoryPath []*MerklePathItem `json:"historyPath"` // Merkle path from Root to the meta-root.
+}
+
+// KeyHistoryProof proves the sequence of values a leaf held across the
+// versions of a tree, each linked to a single root history meta-root.
+type KeyHistoryProof struct {
+	Index    int               `json:"index"`    // Index of the leaf.
+	MetaRoot *big.Int          `json:"metaRoot"` // Meta-root of the root history.
+	Entries  []KeyHistoryEntry `json:"entries"`  // Proven versions, by increasing version.
+}
+
+// ProveHistory proves the values the leaf at index held at every retained
+// version from fromVersion to toVersion. A version is retained if it was
+// tagged with TagHeight or is the current version; versions in between are
+// not covered. history must record the root of every proven version v at
+// history version v, for example by appending the root of the empty tree and
+// then the root of every commit.
+func (smt *SparseMerkleTree) ProveHistory(index, fromVersion, toVersion int, history *RootHistory) (*KeyHistoryProof, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	if fromVersion > toVersion {
+		return nil, fmt.Errorf("invalid version range: %d to %d", fromVersion, toVersion)
+	}
+
+	type retained struct {
+		version int
+		root    *MerkleNode
+	}
+	var versions []retained
+	for _, tag := range smt.heights {
+		if n := len(versions); n > 0 && versions[n-1].version == tag.head.Version {
+			continue
+		}
+		versions = append(versions, retained{tag.head.Version, tag.root})
+	}
+	if n := len(versions); n == 0 || versions[n-1].version != smt.version {
+		versions = append(versions, retained{smt.version, smt.root})
+	}
+
+	key := getPaddedBinaryString(index, smt.depth)
+	proof := &KeyHistoryProof{Index: index, MetaRoot: history.MetaRoot()}
+	for _, v := range versions {
+		if v.version < fromVersion || v.version > toVersion {
+			continue
+		}
+		root, err := history.RootAt(v.version)
+		if err != nil {
+			return nil, err
+		}
+		if root.Cmp(v.root.Data) != 0 {
+			return nil, fmt.Errorf("root recorded for version %d does not match the tree", v.version)
+		}
+		historyPath, err := history.ProveRoot(v.version)
+		if err != nil {
+			return nil, err
+		}
+		proof.Entries = append(proof.Entries, KeyHistoryEntry{
+			Version:     v.version,
+			Root:        root,
+			Leaf:        smt.leafIn(v.root, key),
+			Path:        smt.generateMerklePath(v.root, key),
+			HistoryPath: historyPath,
+		})
+	}
+	if len(proof.Entries) == 0 {
+		return nil, fmt.Errorf("no retained version from %d to %d", fromVersion, toVersion)
+	}
+	return proof, nil
+}
+
+// VerifyKeyHistory verifies that every entry of proof is included in the
+// state of its version, and that the root of that state was recorded at that
+// version in the history committed to by the trusted metaRoot.
+func VerifyKeyHistory(proof *KeyHistoryProof, metaRoot *big.Int) error {
+	if proof == nil {
+		return fmt.Errorf("%w: nil key history proof", ErrMalformedInput)
+	}
+	if proof.MetaRoot == nil || metaRoot == nil || proof.MetaRoot.Cmp(metaRoot) != 0 {
+		return fmt.Errorf("proof meta-root does not match the trusted meta-root")
+	}
+	if len(proof.Entries) == 0 {
+		return fmt.Errorf("key history proof has no entries")
+	}
+
+	for i, entry := range proof.Entries {
+		if i > 0 && entry.Version <= proof.Entries[i-1].Version {
+			return fmt.Errorf("entry %d: version %d does not follow version %d", i, entry.Version, proof.Entries[i-1].Version)
+		}
+		if entry.Root == nil || !VerifyRootAtVersion(entry.Root, entry.Version, entry.HistoryPath, metaRoot) {
+			return fmt.Errorf("entry %d: invalid history proof for version %d", i, entry.Version)
+		}
+		if err := Verify(&MembershipClaim{Index: proof.Index, Leaf: entry.Leaf, Path: entry.Path}, entry.Root, nil); err != nil {
+			return fmt.Errorf("entry %d: version %d: %w", i, entry.Version, err)
+		}
+	}
+	return nil
+}
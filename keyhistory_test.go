@@ -0,0 +1,54 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProveHistory(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	history := NewRootHistory(4, zeroLeaf)
+	record := func() {
+		version, err := history.Append(smt.Root())
+		assert.NoError(t, err)
+		assert.Equal(t, smt.Head().Version, version)
+	}
+	record()
+
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	record()
+	assert.NoError(t, smt.TagHeight(100))
+	assert.NoError(t, smt.Insert(5, big.NewInt(50)))
+	record()
+	assert.NoError(t, smt.TagHeight(101))
+	assert.NoError(t, smt.Insert(3, big.NewInt(31)))
+	record()
+
+	proof, err := smt.ProveHistory(3, 0, 3, history)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyKeyHistory(proof, history.MetaRoot()))
+	var versions []int
+	var leaves []*big.Int
+	for _, entry := range proof.Entries {
+		versions = append(versions, entry.Version)
+		leaves = append(leaves, entry.Leaf)
+	}
+	assert.Equal(t, []int{1, 2, 3}, versions, "only tagged and current versions are retained")
+	assert.Equal(t, []*big.Int{big.NewInt(30), big.NewInt(30), big.NewInt(31)}, leaves)
+
+	proof, err = smt.ProveHistory(5, 1, 1, history)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyKeyHistory(proof, history.MetaRoot()))
+	assert.Equal(t, zeroLeaf, proof.Entries[0].Leaf)
+
+	proof.Entries[0].Leaf = big.NewInt(50)
+	assert.Error(t, VerifyKeyHistory(proof, history.MetaRoot()))
+	assert.Error(t, VerifyKeyHistory(proof, big.NewInt(1)))
+
+	_, err = smt.ProveHistory(3, 4, 9, history)
+	assert.Error(t, err)
+	_, err = smt.ProveHistory(3, 0, 3, NewRootHistory(4, zeroLeaf))
+	assert.Error(t, err, "versions must be recorded in the history")
+}
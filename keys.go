@@ -0,0 +1,175 @@
+package smt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// KeyDomain separates index derivation schemes so that equal bytes coming
+// from different kinds of identifiers map to unrelated indices.
+type KeyDomain uint64
+
+// Key domains used by the built-in index derivation helpers.
+const (
+	KeyDomainBytes   KeyDomain = iota + 1 // Arbitrary byte strings.
+	KeyDomainAddress                      // 20-byte Ethereum addresses.
+	KeyDomainUUID                         // 16-byte UUIDs.
+	KeyDomainDID                          // Decentralized identifier strings.
+	KeyDomainContent                      // Leaf values of content-addressed sets.
+)
+
+// maxDerivedIndexBits is the number of hash bits that fit in a derived index.
+const maxDerivedIndexBits = 62
+
+// DeriveIndex derives a leaf index from data as the low Depth bits of
+// Poseidon(domain, HashBytes(data)). Trees deeper than 62 levels only use the
+// low 62 bits, since indices are plain ints; DeriveIndexBig derives their
+// full-width indices.
+func (smt *SparseMerkleTree) DeriveIndex(domain KeyDomain, data []byte) int {
+	return deriveIndex(domain, data, smt.depth)
+}
+
+// DeriveIndexBig derives a leaf index from data like DeriveIndex, keeping all
+// Depth bits however deep the tree is. It equals DeriveIndex for trees of at
+// most 62 levels.
+func (smt *SparseMerkleTree) DeriveIndexBig(domain KeyDomain, data []byte) *big.Int {
+	return getBigIndexFromBinaryString(derivedKey(domain, data, smt.depth))
+}
+
+// derivedKey returns the binary key of the full-width index derived from
+// data in a tree of the given depth.
+func derivedKey(domain KeyDomain, data []byte, depth int) string {
+	h := deriveKeyHash(domain, data)
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(depth)), big.NewInt(1))
+	key, _ := getBigPaddedBinaryString(h.And(h, mask), depth)
+	return key
+}
+
+// deriveIndex implements DeriveIndex for a tree of the given depth.
+func deriveIndex(domain KeyDomain, data []byte, depth int) int {
+	return indexFromHash(deriveKeyHash(domain, data), depth)
+}
+
+// indexFromHash returns the low bits of h that address a leaf in a tree of
+// the given depth, at most 62 of them.
+func indexFromHash(h *big.Int, depth int) int {
+	bits := depth
+	if bits > maxDerivedIndexBits {
+		bits = maxDerivedIndexBits
+	}
+
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+	return int(new(big.Int).And(h, mask).Int64())
+}
+
+// IndexFromBytes derives a leaf index from an arbitrary byte string.
+func (smt *SparseMerkleTree) IndexFromBytes(data []byte) int {
+	return smt.DeriveIndex(KeyDomainBytes, data)
+}
+
+// IndexFromAddress derives a leaf index from an Ethereum address.
+func (smt *SparseMerkleTree) IndexFromAddress(address [20]byte) int {
+	return smt.DeriveIndex(KeyDomainAddress, address[:])
+}
+
+// IndexFromUUID derives a leaf index from a UUID.
+func (smt *SparseMerkleTree) IndexFromUUID(uuid [16]byte) int {
+	return smt.DeriveIndex(KeyDomainUUID, uuid[:])
+}
+
+// IndexFromDID derives a leaf index from a decentralized identifier such as
+// "did:example:123".
+func (smt *SparseMerkleTree) IndexFromDID(did string) int {
+	return smt.DeriveIndex(KeyDomainDID, []byte(did))
+}
+
+// deriveKeyHash hashes data into a field element separated by domain.
+func deriveKeyHash(domain KeyDomain, data []byte) *big.Int {
+	dataHash := new(big.Int)
+	if len(data) > 0 {
+		dataHash, _ = poseidon.HashBytes(data)
+	}
+	h, _ := poseidon.Hash([]*big.Int{new(big.Int).SetUint64(uint64(domain)), dataHash})
+	return h
+}
+
+// ErrKeyCollision is returned when a hashed key derives the index of a leaf
+// that belongs to a different key.
+var ErrKeyCollision = errors.New("key collision")
+
+// hashedKey is the original identifier of a leaf inserted by hashed key.
+type hashedKey struct {
+	domain KeyDomain
+	id     []byte
+}
+
+// InsertHashed inserts value at the full-width index derived from id in the
+// given domain, as DeriveIndexBig derives it, and returns that index. The
+// original identifier is stored alongside the leaf, and inserting a different
+// identifier that derives the same index fails with ErrKeyCollision instead
+// of overwriting the leaf.
+func (smt *SparseMerkleTree) InsertHashed(domain KeyDomain, id []byte, value *big.Int) (*big.Int, error) {
+	key := derivedKey(domain, id, smt.depth)
+	index := getBigIndexFromBinaryString(key)
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if _, exists := smt.leaves[key]; exists {
+		owner, hashed := smt.hashedKeys[key]
+		if !hashed {
+			return nil, fmt.Errorf("%w: index %s holds a leaf not inserted by hashed key", ErrKeyCollision, index)
+		}
+		if owner.domain != domain || !bytes.Equal(owner.id, id) {
+			return nil, fmt.Errorf("%w: %x (domain %d) and %x (domain %d) both derive index %s", ErrKeyCollision, owner.id, owner.domain, id, domain, index)
+		}
+	}
+
+	if err := smt.set(key, value); err != nil {
+		return nil, err
+	}
+	if smt.hashedKeys == nil {
+		smt.hashedKeys = make(map[string]hashedKey)
+	}
+	smt.hashedKeys[key] = hashedKey{domain: domain, id: append([]byte(nil), id...)}
+	return index, nil
+}
+
+// HashedKeyAt returns the original identifier of the leaf at index if it was
+// inserted by hashed key.
+func (smt *SparseMerkleTree) HashedKeyAt(index *big.Int) (KeyDomain, []byte, bool) {
+	key, err := smt.bigKey(index)
+	if err != nil {
+		return 0, nil, false
+	}
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	owner, exists := smt.hashedKeys[key]
+	if !exists {
+		return 0, nil, false
+	}
+	return owner.domain, append([]byte(nil), owner.id...), true
+}
+
+// GetHashed returns the value of the leaf inserted with InsertHashed for id in
+// the given domain. It reports false if the derived index is unset or holds a
+// leaf inserted for a different identifier.
+func (smt *SparseMerkleTree) GetHashed(domain KeyDomain, id []byte) (*big.Int, bool) {
+	key := derivedKey(domain, id, smt.depth)
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	owner, hashed := smt.hashedKeys[key]
+	if !hashed || owner.domain != domain || !bytes.Equal(owner.id, id) {
+		return nil, false
+	}
+	value, exists := smt.leaves[key]
+	return copyInt(value), exists
+}
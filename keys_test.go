@@ -0,0 +1,118 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveIndex(t *testing.T) {
+	smt := NewSparseMerkleTree(16, zeroLeaf)
+
+	address := [20]byte{0xde, 0xad, 0xbe, 0xef}
+	index := smt.IndexFromAddress(address)
+	assert.Equal(t, index, smt.IndexFromAddress(address), "derivation must be deterministic")
+	assert.GreaterOrEqual(t, index, 0)
+	assert.Less(t, index, 1<<16)
+
+	assert.NotEqual(t, index, smt.IndexFromBytes(address[:]), "domains must separate equal bytes")
+	assert.NotEqual(t, smt.IndexFromDID("did:example:1"), smt.IndexFromDID("did:example:2"))
+	assert.Equal(t, smt.IndexFromUUID([16]byte{1}), smt.DeriveIndex(KeyDomainUUID, []byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}))
+	assert.Less(t, smt.IndexFromBytes(nil), 1<<16)
+
+	assert.Equal(t, big.NewInt(int64(index)), smt.DeriveIndexBig(KeyDomainAddress, address[:]))
+
+	deep := NewSparseMerkleTree(100, zeroLeaf)
+	assert.GreaterOrEqual(t, deep.IndexFromBytes([]byte("key")), 0)
+	full := deep.DeriveIndexBig(KeyDomainBytes, []byte("key"))
+	assert.LessOrEqual(t, full.BitLen(), 100)
+	assert.Greater(t, full.BitLen(), 62, "deep trees use every bit of the index")
+	assert.Equal(t, int64(deep.IndexFromBytes([]byte("key"))), new(big.Int).And(full, big.NewInt(1<<62-1)).Int64())
+}
+
+func TestInsertHashedDeepTree(t *testing.T) {
+	smt := NewSparseMerkleTree(160, zeroLeaf)
+	index, err := smt.InsertHashed(KeyDomainAddress, []byte("alice"), big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, smt.DeriveIndexBig(KeyDomainAddress, []byte("alice")), index)
+
+	value, ok := smt.GetBig(index)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(1), value)
+	value, ok = smt.GetHashed(KeyDomainAddress, []byte("alice"))
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(1), value)
+	_, id, ok := smt.HashedKeyAt(index)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("alice"), id)
+}
+
+func TestInsertHashedCollision(t *testing.T) {
+	smt := NewSparseMerkleTree(2, zeroLeaf)
+
+	first := []byte("alice")
+	derived, err := smt.InsertHashed(KeyDomainBytes, first, big.NewInt(1))
+	assert.NoError(t, err)
+	index := int(derived.Int64())
+
+	domain, id, ok := smt.HashedKeyAt(derived)
+	assert.True(t, ok)
+	assert.Equal(t, KeyDomainBytes, domain)
+	assert.Equal(t, first, id)
+
+	_, err = smt.InsertHashed(KeyDomainBytes, first, big.NewInt(2))
+	assert.NoError(t, err, "the same key may update its own leaf")
+
+	// A depth-2 tree has four indices, so some other key must collide.
+	var collided bool
+	for i := 0; i < 64 && !collided; i++ {
+		other := []byte{byte(i)}
+		if smt.IndexFromBytes(other) != index {
+			continue
+		}
+		_, err = smt.InsertHashed(KeyDomainBytes, other, big.NewInt(3))
+		assert.ErrorIs(t, err, ErrKeyCollision)
+		collided = true
+	}
+	assert.True(t, collided)
+	assert.Equal(t, big.NewInt(2), smt.leaves[getPaddedBinaryString(index, smt.depth)])
+
+	value, ok := smt.GetHashed(KeyDomainBytes, first)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(2), value)
+	_, ok = smt.GetHashed(KeyDomainUUID, first)
+	assert.False(t, ok, "the same bytes in another domain are a different key")
+
+	smt.Insert(3-index, big.NewInt(4))
+	var plainCollision bool
+	for i := 0; i < 64 && !plainCollision; i++ {
+		other := []byte{byte(i)}
+		if smt.IndexFromBytes(other) == 3-index {
+			_, err = smt.InsertHashed(KeyDomainBytes, other, big.NewInt(5))
+			assert.ErrorIs(t, err, ErrKeyCollision)
+			plainCollision = true
+		}
+	}
+	assert.True(t, plainCollision)
+}
+
+func TestInsertHashedOverwritten(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	alice := []byte("alice")
+	derived, err := smt.InsertHashed(KeyDomainBytes, alice, big.NewInt(42))
+	assert.NoError(t, err)
+	index := int(derived.Int64())
+
+	assert.NoError(t, smt.Insert(index, big.NewInt(7)))
+	_, ok := smt.GetHashed(KeyDomainBytes, alice)
+	assert.False(t, ok, "a plain insert takes the leaf away from alice")
+	_, _, ok = smt.HashedKeyAt(derived)
+	assert.False(t, ok)
+
+	bob, err := smt.InsertKey("bob", big.NewInt(1))
+	assert.NoError(t, err)
+	assert.NoError(t, smt.Insert(bob, big.NewInt(2)))
+	_, ok = smt.KeyName(bob)
+	assert.False(t, ok, "a plain insert drops the key name of the previous leaf")
+}
@@ -0,0 +1,41 @@
+package smt
+
+import (
+	"fmt"
+	"time"
+)
+
+// MappingReport attests that a migrated tree holds the same leaves as the
+// tree it was migrated from, for operators to publish alongside the new root.
+type MappingReport struct {
+	From      TreeHead  `json:"from"`      // Head of the tree before migration, under the old hasher.
+	To        TreeHead  `json:"to"`        // Head of the migrated tree, under the new hasher.
+	LeafCount int       `json:"leafCount"` // Number of leaves carried over.
+	Migrated  time.Time `json:"migrated"`  // Time the migration finished.
+}
+
+// MigrateHasher rebuilds the tree with newHasher for its inner nodes and
+// returns the new tree, leaving the original unchanged, together with a
+// report mapping the old root to the new one. The leaves and their metadata
+// are carried over as they are, and disjoint subtrees are hashed in
+// parallel, so newHasher must be safe for concurrent use. Proofs of the new
+// tree are checked with VerifyMerklePathWith.
+func (smt *SparseMerkleTree) MigrateHasher(newHasher Hasher) (*SparseMerkleTree, MappingReport, error) {
+	if newHasher == nil {
+		return nil, MappingReport{}, fmt.Errorf("nil hasher")
+	}
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	migrated, err := newTreeWithHasher(smt.depth, smt.zeroLeaf, newHasher)
+	if err != nil {
+		return nil, MappingReport{}, err
+	}
+	if err := smt.rebuildInto(migrated); err != nil {
+		return nil, MappingReport{}, err
+	}
+
+	report := MappingReport{From: smt.head(), To: migrated.Head(), LeafCount: len(smt.leaves), Migrated: time.Now()}
+	return migrated, report, nil
+}
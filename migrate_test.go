@@ -0,0 +1,54 @@
+package smt
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// sha256Hasher hashes nodes with SHA-256, reduced into the field.
+var sha256Hasher = NewHasher("sha256-mod-q", func(left, right *big.Int) (*big.Int, error) {
+	var data [64]byte
+	left.FillBytes(data[:32])
+	right.FillBytes(data[32:])
+	sum := sha256.Sum256(data[:])
+	return new(big.Int).Mod(new(big.Int).SetBytes(sum[:]), constants.Q), nil
+})
+
+func TestMigrateHasher(t *testing.T) {
+	smt := NewSparseMerkleTree(16, zeroLeaf)
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, smt.Insert(i*7, big.NewInt(int64(i+1))))
+	}
+	index, err := smt.InsertKey("alice", big.NewInt(99))
+	assert.NoError(t, err)
+	root := smt.Root()
+
+	migrated, report, err := smt.MigrateHasher(sha256Hasher)
+	assert.NoError(t, err)
+	assert.Equal(t, root, smt.Root(), "the original tree is unchanged")
+	assert.NotEqual(t, root, migrated.Root())
+
+	assert.Equal(t, root, report.From.Root)
+	assert.Equal(t, PoseidonHasher.Fingerprint(), report.From.Hasher)
+	assert.Equal(t, migrated.Root(), report.To.Root)
+	assert.Equal(t, "sha256-mod-q", report.To.Hasher)
+	assert.Equal(t, 21, report.LeafCount)
+
+	path, err := migrated.GenerateMerklePath(index)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePathWith(sha256Hasher, big.NewInt(99), path, migrated.Root()))
+	assert.False(t, VerifyMerklePath(big.NewInt(99), path, migrated.Root()))
+	name, _ := migrated.KeyName(index)
+	assert.Equal(t, "alice", name)
+
+	// Updating the migrated tree matches building it from scratch.
+	assert.NoError(t, migrated.Insert(1, big.NewInt(5)))
+	expected, _ := newTreeWithHasher(16, zeroLeaf, sha256Hasher)
+	assert.NoError(t, smt.Insert(1, big.NewInt(5)))
+	assert.NoError(t, smt.rebuildInto(expected))
+	assert.Equal(t, expected.Root(), migrated.Root())
+}
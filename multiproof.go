@@ -0,0 +1,124 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// MultiProof proves the values of several leaves at once. Nodes shared by
+// their paths, and siblings that are themselves on a proven path, appear
+// only once or not at all.
+type MultiProof struct {
+	Depth    int        `json:"depth"`    // Depth of the tree.
+	Indices  []int      `json:"indices"`  // Proven indices, in increasing order.
+	Leaves   []*big.Int `json:"leaves"`   // Value of each proven leaf, the zero leaf if unset.
+	Siblings []*big.Int `json:"siblings"` // Sibling hashes not computable from the leaves, from the leaves up and by position within a level.
+}
+
+// GenerateMultiProof generates one proof of the leaves at indices, which may
+// be unset and may repeat.
+func (smt *SparseMerkleTree) GenerateMultiProof(indices []int) (*MultiProof, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	unique := make(map[int]bool, len(indices))
+	for _, index := range indices {
+		if err := checkIndex(index, smt.depth); err != nil {
+			return nil, err
+		}
+		unique[index] = true
+	}
+	proof := &MultiProof{Depth: smt.depth, Indices: make([]int, 0, len(unique))}
+	for index := range unique {
+		proof.Indices = append(proof.Indices, index)
+	}
+	sort.Ints(proof.Indices)
+	for _, index := range proof.Indices {
+		proof.Leaves = append(proof.Leaves, smt.leafOrZero(getPaddedBinaryString(index, smt.depth)))
+	}
+
+	positions := append([]int(nil), proof.Indices...)
+	for height := 0; height < smt.depth; height++ {
+		var parents []int
+		for i := 0; i < len(positions); i++ {
+			position := positions[i]
+			if position%2 == 0 && i+1 < len(positions) && positions[i+1] == position+1 {
+				i++
+			} else {
+				proof.Siblings = append(proof.Siblings, smt.hashAt(height, position^1))
+			}
+			parents = append(parents, position/2)
+		}
+		positions = parents
+	}
+	return proof, nil
+}
+
+// hashAt returns the hash of the node at the given height and position within
+// its level. The caller must hold the lock.
+func (smt *SparseMerkleTree) hashAt(height, position int) *big.Int {
+	return smt.prefixHash(getPaddedBinaryString(position, smt.depth-height))
+}
+
+// VerifyMultiProof verifies a multiproof against the expected root of a
+// Poseidon tree.
+func VerifyMultiProof(proof *MultiProof, expectedRoot *big.Int) bool {
+	root, err := proof.root(PoseidonHasher)
+	return err == nil && expectedRoot != nil && root.Cmp(expectedRoot) == 0
+}
+
+// root recomputes the root committed to by the proof.
+func (proof *MultiProof) root(hasher Hasher) (*big.Int, error) {
+	if proof == nil || len(proof.Indices) == 0 || len(proof.Indices) != len(proof.Leaves) {
+		return nil, fmt.Errorf("multiproof has %d leaves for its indices", len(proof.Leaves))
+	}
+	for i, index := range proof.Indices {
+		if err := checkIndex(index, proof.Depth); err != nil {
+			return nil, err
+		}
+		if i > 0 && index <= proof.Indices[i-1] {
+			return nil, fmt.Errorf("multiproof indices are not strictly increasing")
+		}
+		if proof.Leaves[i] == nil {
+			return nil, fmt.Errorf("nil leaf for index %d", index)
+		}
+	}
+
+	positions := append([]int(nil), proof.Indices...)
+	hashes := append([]*big.Int(nil), proof.Leaves...)
+	next := 0
+	for height := 0; height < proof.Depth; height++ {
+		var parents []int
+		var parentHashes []*big.Int
+		for i := 0; i < len(positions); i++ {
+			position, left := positions[i], hashes[i]
+			var right *big.Int
+			if position%2 == 0 && i+1 < len(positions) && positions[i+1] == position+1 {
+				right = hashes[i+1]
+				i++
+			} else {
+				if next == len(proof.Siblings) || proof.Siblings[next] == nil {
+					return nil, fmt.Errorf("missing sibling at height %d", height)
+				}
+				right = proof.Siblings[next]
+				next++
+				if position%2 == 1 {
+					left, right = right, left
+				}
+			}
+
+			parent, err := hasher.Hash(left, right)
+			if err != nil {
+				return nil, err
+			}
+			parents = append(parents, position/2)
+			parentHashes = append(parentHashes, parent)
+		}
+		positions, hashes = parents, parentHashes
+	}
+	if next != len(proof.Siblings) {
+		return nil, fmt.Errorf("multiproof has %d unused siblings", len(proof.Siblings)-next)
+	}
+	return hashes[0], nil
+}
@@ -0,0 +1,47 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiProof(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	for i := 0; i < 40; i++ {
+		assert.NoError(t, smt.Insert(i*5, big.NewInt(int64(i+1))))
+	}
+
+	indices := []int{10, 0, 5, 200, 11, 10}
+	proof, err := smt.GenerateMultiProof(indices)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 5, 10, 11, 200}, proof.Indices)
+	assert.Equal(t, zeroLeaf, proof.Leaves[3])
+	assert.True(t, VerifyMultiProof(proof, smt.Root()))
+	assert.Less(t, len(proof.Siblings), 5*8, "shared nodes are not repeated")
+
+	tampered := *proof
+	tampered.Leaves = append([]*big.Int(nil), proof.Leaves...)
+	tampered.Leaves[1] = big.NewInt(99)
+	assert.False(t, VerifyMultiProof(&tampered, smt.Root()))
+
+	moved := *proof
+	moved.Indices = []int{0, 5, 10, 12, 200}
+	assert.False(t, VerifyMultiProof(&moved, smt.Root()))
+
+	truncated := *proof
+	truncated.Siblings = proof.Siblings[:len(proof.Siblings)-1]
+	assert.False(t, VerifyMultiProof(&truncated, smt.Root()))
+
+	single, err := smt.GenerateMultiProof([]int{5})
+	assert.NoError(t, err)
+	path, err := smt.GenerateMerklePath(5)
+	assert.NoError(t, err)
+	assert.Len(t, single.Siblings, len(path))
+	assert.True(t, VerifyMultiProof(single, smt.Root()))
+
+	_, err = smt.GenerateMultiProof([]int{256})
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	assert.False(t, VerifyMultiProof(&MultiProof{Depth: 8}, smt.Root()))
+}
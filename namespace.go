@@ -0,0 +1,129 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// NamespacedTree is a sparse Merkle tree whose leaves are addressed by a
+// namespace and an id within it. The namespace selects a subtree, so every
+// namespace has its own root and proofs can be scoped to a single namespace.
+type NamespacedTree struct {
+	tree          *SparseMerkleTree
+	namespaceBits int // Number of high path bits selecting the namespace.
+	idBits        int // Number of low path bits selecting the id.
+}
+
+// NamespaceProof proves that a leaf is part of a namespace, and that the
+// namespace root is part of the tree.
+type NamespaceProof struct {
+	Namespace     int               `json:"namespace"`     // Namespace of the leaf.
+	ID            int               `json:"id"`            // Id of the leaf within its namespace.
+	Leaf          *big.Int          `json:"leaf"`          // Value of the leaf.
+	NamespaceRoot *big.Int          `json:"namespaceRoot"` // Root of the namespace subtree.
+	Path          []*MerklePathItem `json:"path"`          // Merkle path from the leaf to the namespace root.
+	NamespacePath []*MerklePathItem `json:"namespacePath"` // Merkle path from the namespace root to the tree root.
+}
+
+// NewNamespacedTree creates a tree with 2^namespaceBits namespaces of 2^idBits
+// leaves each.
+func NewNamespacedTree(namespaceBits, idBits int, zeroLeaf *big.Int) (*NamespacedTree, error) {
+	if namespaceBits < 1 || idBits < 1 || namespaceBits+idBits > maxDerivedIndexBits {
+		return nil, fmt.Errorf("invalid namespace layout: %d namespace bits and %d id bits", namespaceBits, idBits)
+	}
+	return &NamespacedTree{tree: NewSparseMerkleTree(namespaceBits+idBits, zeroLeaf), namespaceBits: namespaceBits, idBits: idBits}, nil
+}
+
+// Tree returns the underlying sparse Merkle tree.
+func (t *NamespacedTree) Tree() *SparseMerkleTree {
+	return t.tree
+}
+
+// Root returns the root hash of the tree.
+func (t *NamespacedTree) Root() *big.Int {
+	return t.tree.Root()
+}
+
+// Insert inserts a leaf with the given value at id in namespace ns.
+func (t *NamespacedTree) Insert(ns, id int, value *big.Int) error {
+	index, err := t.index(ns, id)
+	if err != nil {
+		return err
+	}
+	return t.tree.Insert(index, value)
+}
+
+// Get returns the value of the leaf at id in namespace ns, if one was
+// inserted.
+func (t *NamespacedTree) Get(ns, id int) (*big.Int, bool) {
+	index, err := t.index(ns, id)
+	if err != nil {
+		return nil, false
+	}
+	return t.tree.Get(index)
+}
+
+// NamespaceRoot returns the root of the subtree holding namespace ns.
+func (t *NamespacedTree) NamespaceRoot(ns int) (*big.Int, error) {
+	if err := checkIndex(ns, t.namespaceBits); err != nil {
+		return nil, fmt.Errorf("namespace: %w", err)
+	}
+	roots, err := t.tree.SubtreeHashes([]string{getPaddedBinaryString(ns, t.namespaceBits)})
+	if err != nil {
+		return nil, err
+	}
+	return roots[0], nil
+}
+
+// Prove returns a proof for the leaf at id in namespace ns, which must have
+// been inserted.
+func (t *NamespacedTree) Prove(ns, id int) (*NamespaceProof, error) {
+	index, err := t.index(ns, id)
+	if err != nil {
+		return nil, err
+	}
+
+	t.tree.mu.RLock()
+	defer t.tree.mu.RUnlock()
+
+	key := getPaddedBinaryString(index, t.tree.depth)
+	leaf, exists := t.tree.leaves[key]
+	if !exists {
+		return nil, fmt.Errorf("no leaf exists at key: %s", key)
+	}
+
+	path := t.tree.generateMerklePath(t.tree.root, key)
+	return &NamespaceProof{
+		Namespace:     ns,
+		ID:            id,
+		Leaf:          leaf,
+		NamespaceRoot: t.tree.prefixHash(key[:t.namespaceBits]),
+		Path:          path[:t.idBits],
+		NamespacePath: path[t.idBits:],
+	}, nil
+}
+
+// index returns the leaf index of id in namespace ns.
+func (t *NamespacedTree) index(ns, id int) (int, error) {
+	if err := checkIndex(ns, t.namespaceBits); err != nil {
+		return 0, fmt.Errorf("namespace: %w", err)
+	}
+	if err := checkIndex(id, t.idBits); err != nil {
+		return 0, fmt.Errorf("id: %w", err)
+	}
+	return ns<<t.idBits | id, nil
+}
+
+// VerifyInNamespace checks the scoped part of proof: that its leaf is at its
+// id under namespaceRoot.
+func VerifyInNamespace(proof *NamespaceProof, namespaceRoot *big.Int) bool {
+	return proof != nil && namespaceRoot != nil && proof.Leaf != nil &&
+		pathMatchesIndex(proof.Path, proof.ID) && VerifyMerklePath(proof.Leaf, proof.Path, namespaceRoot)
+}
+
+// VerifyNamespaceProof checks that the leaf of proof is at its id in its
+// namespace of the tree with the given root.
+func VerifyNamespaceProof(proof *NamespaceProof, root *big.Int) bool {
+	return proof != nil && VerifyInNamespace(proof, proof.NamespaceRoot) && root != nil &&
+		pathMatchesIndex(proof.NamespacePath, proof.Namespace) && VerifyMerklePath(proof.NamespaceRoot, proof.NamespacePath, root)
+}
@@ -0,0 +1,50 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespacedTree(t *testing.T) {
+	tree, err := NewNamespacedTree(4, 12, zeroLeaf)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tree.Insert(3, 100, big.NewInt(1)))
+	assert.NoError(t, tree.Insert(3, 200, big.NewInt(2)))
+	assert.NoError(t, tree.Insert(9, 100, big.NewInt(3)))
+	assert.Error(t, tree.Insert(16, 0, big.NewInt(1)))
+	assert.Error(t, tree.Insert(0, 1<<12, big.NewInt(1)))
+
+	value, ok := tree.Get(9, 100)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(3), value)
+
+	root3, err := tree.NamespaceRoot(3)
+	assert.NoError(t, err)
+	empty, err := tree.NamespaceRoot(5)
+	assert.NoError(t, err)
+	expectedEmpty, _ := EmptyRoot(12, nil, zeroLeaf)
+	assert.Equal(t, expectedEmpty, empty)
+
+	// Writes to other namespaces do not move a namespace root.
+	assert.NoError(t, tree.Insert(9, 101, big.NewInt(4)))
+	unchanged, _ := tree.NamespaceRoot(3)
+	assert.Equal(t, root3, unchanged)
+
+	proof, err := tree.Prove(3, 200)
+	assert.NoError(t, err)
+	assert.Len(t, proof.Path, 12)
+	assert.Len(t, proof.NamespacePath, 4)
+	assert.True(t, VerifyInNamespace(proof, root3))
+	assert.True(t, VerifyNamespaceProof(proof, tree.Root()))
+
+	proof.ID = 201
+	assert.False(t, VerifyInNamespace(proof, root3))
+	proof.ID, proof.Namespace = 200, 4
+	assert.False(t, VerifyNamespaceProof(proof, tree.Root()))
+
+	_, err = tree.Prove(3, 300)
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// NonMembershipProof proves that the leaf at Index holds the zero leaf, either
+// because it was never inserted or because it was set to the zero leaf.
+type NonMembershipProof struct {
+	Index int               `json:"index"` // Index of the leaf.
+	Path  []*MerklePathItem `json:"path"`  // Merkle path of the zero leaf at Index.
+}
+
+// ProveNonMembership returns a proof that the leaf at index holds the zero
+// leaf. It fails if the leaf holds any other value.
+func (smt *SparseMerkleTree) ProveNonMembership(index int) (*NonMembershipProof, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	if value := smt.leafOrZero(key); value.Cmp(smt.zeroLeaf) != 0 {
+		return nil, fmt.Errorf("leaf at key %s holds %s", key, value)
+	}
+	return &NonMembershipProof{Index: index, Path: smt.generateMerklePath(smt.root, key)}, nil
+}
+
+// VerifyNonMembership checks that proof shows the leaf at its index to hold
+// zeroLeaf in the tree with the given root. The zero leaf is supplied by the
+// verifier rather than the proof, so a prover cannot pass off a member's
+// value as the default.
+func VerifyNonMembership(proof *NonMembershipProof, zeroLeaf, root *big.Int) bool {
+	return proof != nil && verifyClaimPath(proof.Index, zeroLeaf, proof.Path, root)
+}
@@ -0,0 +1,37 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonMembership(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(2, big.NewInt(20)))
+	assert.NoError(t, smt.Insert(3, zeroLeaf))
+
+	proof, err := smt.ProveNonMembership(9)
+	assert.NoError(t, err)
+	assert.True(t, VerifyNonMembership(proof, zeroLeaf, smt.Root()))
+
+	explicit, err := smt.ProveNonMembership(3)
+	assert.NoError(t, err, "a leaf set to the zero leaf holds the default")
+	assert.True(t, VerifyNonMembership(explicit, zeroLeaf, smt.Root()))
+
+	_, err = smt.ProveNonMembership(2)
+	assert.Error(t, err)
+	_, err = smt.ProveNonMembership(16)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+
+	moved := *proof
+	moved.Index = 2
+	assert.False(t, VerifyNonMembership(&moved, zeroLeaf, smt.Root()))
+
+	// A member's path does not verify as non-membership.
+	path, err := smt.GenerateMerklePath(2)
+	assert.NoError(t, err)
+	assert.False(t, VerifyNonMembership(&NonMembershipProof{Index: 2, Path: path}, zeroLeaf, smt.Root()))
+	assert.False(t, VerifyNonMembership(proof, big.NewInt(20), smt.Root()))
+}
@@ -0,0 +1,134 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// ApplyParallel applies mutations to distinct indices as a single commit,
+// splitting them into disjoint subtrees hashed on up to workers goroutines,
+// and returns the resulting root. Concurrency is further bounded by the
+// tree's hash pool. The resulting tree does not depend on the number of
+// workers or on the order of the mutations. A non-positive workers uses the
+// size of the hash pool.
+func (smt *SparseMerkleTree) ApplyParallel(mutations []Mutation, workers int) (*big.Int, error) {
+	keys := make([]string, len(mutations))
+	values := make(map[string]*big.Int, len(mutations))
+	for i, m := range mutations {
+		if err := checkIndex(m.Index, smt.depth); err != nil {
+			return nil, fmt.Errorf("mutation %d: %w", i, err)
+		}
+		if err := checkValue(m.Value); err != nil {
+			return nil, fmt.Errorf("mutation %d: %w", i, err)
+		}
+		keys[i] = getPaddedBinaryString(m.Index, smt.depth)
+		if _, duplicate := values[keys[i]]; duplicate {
+			return nil, fmt.Errorf("mutation %d: duplicate index %d", i, m.Index)
+		}
+		values[keys[i]] = copyInt(m.Value)
+	}
+	sort.Strings(keys)
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	for i, m := range mutations {
+		if err := smt.checkNotTombstone(m.Value); err != nil {
+			return nil, fmt.Errorf("mutation %d: %w", i, err)
+		}
+	}
+	if err := smt.checkCapacity(values); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		smt.setLeaf(key, values[key])
+	}
+	smt.applyKeys(keys, values, workers)
+	smt.commit()
+	return copyInt(smt.root.Data), nil
+}
+
+// applyKeys inserts the leaves with the given sorted keys into the tree,
+// splitting them by prefix into subtrees hashed on separate goroutines. Each
+// node above the leaves is hashed once. The leaves map must already hold the
+// new values. A compressed tree inserts them on the calling goroutine. The
+// caller must hold the write lock.
+func (smt *SparseMerkleTree) applyKeys(keys []string, values map[string]*big.Int, workers int) {
+	if smt.compressed {
+		smt.root = smt.insertBatch(smt.root, keys, values, 0)
+		return
+	}
+	pool := smt.pool()
+	if workers <= 0 {
+		workers = pool.Size()
+	}
+	split := 0
+	for 1<<split < workers && split < smt.depth {
+		split++
+	}
+
+	groups := make(map[string][]string)
+	var prefixes []string
+	for _, key := range keys {
+		prefix := key[:split]
+		if _, exists := groups[prefix]; !exists {
+			prefixes = append(prefixes, prefix)
+		}
+		groups[prefix] = append(groups[prefix], key)
+	}
+
+	subtrees := make([]*MerkleNode, len(prefixes))
+	pool.run(len(prefixes), workers, func(i int) {
+		subtrees[i] = smt.insertBatch(smt.nodeAt(prefixes[i]), groups[prefixes[i]], values, split)
+	})
+
+	for i, prefix := range prefixes {
+		smt.root = smt.attachNode(smt.root, prefix, 0, subtrees[i])
+	}
+	smt.rehashPrefixes(smt.root, "", groups, split)
+}
+
+// attachNode returns a copy of current, at the specified depth, with node
+// placed at the given binary path prefix. The nodes above node are copied or
+// created, and their hashes are left for the caller to recompute.
+func (smt *SparseMerkleTree) attachNode(current *MerkleNode, prefix string, depth int, node *MerkleNode) *MerkleNode {
+	if depth == len(prefix) {
+		return node
+	}
+
+	current = copyNode(current)
+	if getPathBit(prefix, depth) == 0 {
+		current.Left = smt.attachNode(current.Left, prefix, depth+1, node)
+	} else {
+		current.Right = smt.attachNode(current.Right, prefix, depth+1, node)
+	}
+	return current
+}
+
+// rehashPrefixes recomputes the hashes of the nodes above depth split whose
+// subtrees contain one of the updated prefixes. These nodes were all copied
+// by attachNode, so no committed node is modified.
+func (smt *SparseMerkleTree) rehashPrefixes(node *MerkleNode, prefix string, groups map[string][]string, split int) {
+	if len(prefix) == split || !hasGroupWithPrefix(groups, prefix) {
+		return
+	}
+
+	if node.Left != nil {
+		smt.rehashPrefixes(node.Left, prefix+"0", groups, split)
+	}
+	if node.Right != nil {
+		smt.rehashPrefixes(node.Right, prefix+"1", groups, split)
+	}
+	node.Data = smt.hashNode(node.Left, node.Right, smt.depth-len(prefix))
+}
+
+// hasGroupWithPrefix reports whether any group key starts with prefix.
+func hasGroupWithPrefix(groups map[string][]string, prefix string) bool {
+	for group := range groups {
+		if len(group) >= len(prefix) && group[:len(prefix)] == prefix {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package smt
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyParallelDeterminism(t *testing.T) {
+	var mutations []Mutation
+	for i := 0; i < 40; i++ {
+		mutations = append(mutations, Mutation{Index: i * 3, Value: big.NewInt(int64(i * 7))})
+	}
+
+	expected := NewSparseMerkleTree(7, zeroLeaf)
+	expected.Insert(1, big.NewInt(1))
+	_, err := expected.ApplyAtomic(mutations)
+	assert.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	for _, workers := range []int{1, 2, 3, 8, 64, 0} {
+		shuffled := append([]Mutation(nil), mutations...)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		tree := NewSparseMerkleTree(7, zeroLeaf)
+		tree.Insert(1, big.NewInt(1))
+		root, err := tree.ApplyParallel(shuffled, workers)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.root.Data, root, "workers: %d", workers)
+		assert.Equal(t, expected.root, tree.root, "the node set must not depend on the worker count")
+		assert.Equal(t, expected.leaves, tree.leaves)
+	}
+}
+
+func TestApplyParallelRejectsDuplicates(t *testing.T) {
+	tree := NewSparseMerkleTree(3, zeroLeaf)
+	before := tree.root.Data
+
+	_, err := tree.ApplyParallel([]Mutation{{Index: 1, Value: big.NewInt(1)}, {Index: 1, Value: big.NewInt(2)}}, 2)
+	assert.Error(t, err)
+	assert.Equal(t, before, tree.root.Data)
+}
@@ -0,0 +1,122 @@
+package smt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrStaleCursor is returned when resuming a chunked operation after the tree
+// has changed.
+var ErrStaleCursor = errors.New("stale cursor")
+
+// Cursor is the resumption point of a chunked operation that stopped at a
+// context deadline.
+type Cursor struct {
+	Version  int `json:"version"`  // Version of the tree the operation runs against.
+	Position int `json:"position"` // Number of items already completed.
+}
+
+// NewBundleContext is NewBundle for jobs too large to finish in one request.
+// When ctx is done it returns the proofs completed so far together with a
+// cursor; passing the cursor back continues with the next proof. The result
+// of a call that finishes has a nil cursor. Members are proven before
+// non-members, and resuming fails with ErrStaleCursor if the tree has been
+// modified in between.
+func (smt *SparseMerkleTree) NewBundleContext(ctx context.Context, members, nonMembers []int, resume *Cursor) (*Bundle, *Cursor, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	for i, index := range members {
+		if err := checkIndex(index, smt.depth); err != nil {
+			return nil, nil, fmt.Errorf("member %d: %w", i, err)
+		}
+	}
+	for i, index := range nonMembers {
+		if err := checkIndex(index, smt.depth); err != nil {
+			return nil, nil, fmt.Errorf("non-member %d: %w", i, err)
+		}
+	}
+	position, err := smt.resumePosition(resume)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bundle := &Bundle{Head: smt.head()}
+	for ; position < len(members)+len(nonMembers); position++ {
+		if ctx.Err() != nil {
+			return bundle, &Cursor{Version: smt.version, Position: position}, nil
+		}
+
+		if position < len(members) {
+			index := members[position]
+			key := getPaddedBinaryString(index, smt.depth)
+			leaf, exists := smt.leaves[key]
+			if !exists {
+				return nil, nil, fmt.Errorf("no leaf exists at key: %s", key)
+			}
+			bundle.Inclusions = append(bundle.Inclusions, BundleProof{Index: index, Key: smt.keyName(key), Leaf: copyInt(leaf), Path: smt.generateMerklePath(smt.root, key)})
+			continue
+		}
+
+		index := nonMembers[position-len(members)]
+		key := getPaddedBinaryString(index, smt.depth)
+		if _, exists := smt.leaves[key]; exists {
+			return nil, nil, fmt.Errorf("leaf exists at key: %s", key)
+		}
+		bundle.Exclusions = append(bundle.Exclusions, BundleProof{Index: index, Key: smt.keyName(key), Path: smt.generateMerklePath(smt.root, key)})
+	}
+	return bundle, nil, nil
+}
+
+// WriteSnapshotContext is WriteSnapshot for trees too large to export in one
+// request. When ctx is done it stops after the records written so far and
+// returns a cursor; passing the cursor back writes the remaining leaf records
+// without a header, so the concatenated chunks form one snapshot. A call that
+// finishes returns a nil cursor. Resuming fails with ErrStaleCursor if the
+// tree has been modified in between.
+func (smt *SparseMerkleTree) WriteSnapshotContext(ctx context.Context, w io.Writer, resume *Cursor) (*Cursor, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	position, err := smt.resumePosition(resume)
+	if err != nil {
+		return nil, err
+	}
+	leaves := smt.snapshotLeaves()
+
+	encoder := json.NewEncoder(w)
+	if resume == nil {
+		head := smt.head()
+		head.Timestamp = head.Timestamp.UTC()
+		if err := encoder.Encode(SnapshotHeader{Head: head, LeafCount: len(leaves)}); err != nil {
+			return nil, err
+		}
+	}
+	for ; position < len(leaves); position++ {
+		if ctx.Err() != nil {
+			return &Cursor{Version: smt.version, Position: position}, nil
+		}
+		if err := encoder.Encode(leaves[position]); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// resumePosition returns the position to continue a chunked operation from.
+// The caller must hold the lock.
+func (smt *SparseMerkleTree) resumePosition(resume *Cursor) (int, error) {
+	if resume == nil {
+		return 0, nil
+	}
+	if resume.Version != smt.version {
+		return 0, fmt.Errorf("%w: cursor for version %d, tree is at version %d", ErrStaleCursor, resume.Version, smt.version)
+	}
+	if resume.Position < 0 {
+		return 0, fmt.Errorf("invalid cursor position: %d", resume.Position)
+	}
+	return resume.Position, nil
+}
@@ -0,0 +1,79 @@
+package smt
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cancelAfter returns a context that is done after its Err method has been
+// called n times.
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n == 0 {
+		return context.DeadlineExceeded
+	}
+	c.n--
+	return nil
+}
+
+func TestNewBundleContextResumes(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, smt.Insert(i, big.NewInt(int64(i+1))))
+	}
+	members, nonMembers := []int{0, 1, 2, 3}, []int{8, 9}
+
+	var inclusions, exclusions []BundleProof
+	var cursor *Cursor
+	for chunks := 0; ; chunks++ {
+		bundle, next, err := smt.NewBundleContext(&cancelAfter{Context: context.Background(), n: 2}, members, nonMembers, cursor)
+		assert.NoError(t, err)
+		inclusions = append(inclusions, bundle.Inclusions...)
+		exclusions = append(exclusions, bundle.Exclusions...)
+		if cursor = next; cursor == nil {
+			assert.Equal(t, 2, chunks)
+			break
+		}
+	}
+
+	full, err := smt.NewBundle(members, nonMembers)
+	assert.NoError(t, err)
+	assert.Equal(t, full.Inclusions, inclusions)
+	assert.Equal(t, full.Exclusions, exclusions)
+
+	_, cursor, _ = smt.NewBundleContext(&cancelAfter{Context: context.Background(), n: 1}, members, nonMembers, nil)
+	assert.NoError(t, smt.Insert(5, big.NewInt(6)))
+	_, _, err = smt.NewBundleContext(context.Background(), members, nonMembers, cursor)
+	assert.ErrorIs(t, err, ErrStaleCursor)
+}
+
+func TestWriteSnapshotContextResumes(t *testing.T) {
+	smt := NewSparseMerkleTree(6, zeroLeaf)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, smt.Insert(i*5, big.NewInt(int64(i))))
+	}
+
+	var chunked bytes.Buffer
+	var cursor *Cursor
+	for {
+		next, err := smt.WriteSnapshotContext(&cancelAfter{Context: context.Background(), n: 3}, &chunked, cursor)
+		assert.NoError(t, err)
+		if cursor = next; cursor == nil {
+			break
+		}
+	}
+
+	var whole bytes.Buffer
+	assert.NoError(t, smt.WriteSnapshot(&whole))
+	assert.Equal(t, whole.String(), chunked.String())
+	_, err := ValidateSnapshot(&chunked)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,82 @@
+package smt
+
+import (
+	"runtime"
+	"sync"
+)
+
+// HashPool bounds the number of subtrees hashed concurrently. A pool can be
+// shared by many trees so that simultaneous parallel operations do not
+// oversubscribe the CPUs.
+type HashPool struct {
+	slots chan struct{}
+}
+
+// NewHashPool creates a pool allowing size concurrent hashing jobs. A
+// non-positive size uses GOMAXPROCS.
+func NewHashPool(size int) *HashPool {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	return &HashPool{slots: make(chan struct{}, size)}
+}
+
+// Size returns the number of jobs the pool runs concurrently.
+func (p *HashPool) Size() int {
+	return cap(p.slots)
+}
+
+// run calls fn for every job index in [0, jobs) on up to workers goroutines,
+// holding a pool slot for the duration of each call, and waits for all calls
+// to return.
+func (p *HashPool) run(jobs, workers int, fn func(i int)) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				p.slots <- struct{}{}
+				fn(i)
+				<-p.slots
+			}
+		}()
+	}
+	for i := 0; i < jobs; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+var (
+	defaultHashPoolOnce sync.Once
+	defaultHashPool     *HashPool
+)
+
+// DefaultHashPool returns the package-level pool used by trees that were not
+// given one with SetHashPool. It allows GOMAXPROCS concurrent jobs.
+func DefaultHashPool() *HashPool {
+	defaultHashPoolOnce.Do(func() {
+		defaultHashPool = NewHashPool(0)
+	})
+	return defaultHashPool
+}
+
+// SetHashPool makes the tree run its parallel hashing on pool. A nil pool
+// restores the default pool.
+func (smt *SparseMerkleTree) SetHashPool(pool *HashPool) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	smt.hashPool = pool
+}
+
+// pool returns the hash pool of the tree. The caller must hold the lock.
+func (smt *SparseMerkleTree) pool() *HashPool {
+	if smt.hashPool != nil {
+		return smt.hashPool
+	}
+	return DefaultHashPool()
+}
@@ -0,0 +1,63 @@
+package smt
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashPoolBoundsConcurrency(t *testing.T) {
+	pool := NewHashPool(2)
+	assert.Equal(t, 2, pool.Size())
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	for caller := 0; caller < 3; caller++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.run(8, 4, func(i int) {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				atomic.AddInt32(&running, -1)
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak, int32(2), "callers sharing a pool must not exceed its size")
+}
+
+func TestSharedHashPool(t *testing.T) {
+	pool := NewHashPool(1)
+	a, b := NewSparseMerkleTree(5, zeroLeaf), NewSparseMerkleTree(5, zeroLeaf)
+	a.SetHashPool(pool)
+	b.SetHashPool(pool)
+
+	var mutations []Mutation
+	for i := 0; i < 32; i++ {
+		mutations = append(mutations, Mutation{Index: i, Value: big.NewInt(int64(i))})
+	}
+
+	var wg sync.WaitGroup
+	for _, tree := range []*SparseMerkleTree{a, b} {
+		wg.Add(1)
+		go func(tree *SparseMerkleTree) {
+			defer wg.Done()
+			_, err := tree.ApplyParallel(mutations, 4)
+			assert.NoError(t, err)
+		}(tree)
+	}
+	wg.Wait()
+
+	assert.Equal(t, NewDeterministicSparseMerkleTree(5, zeroLeaf).root.Data, a.root.Data)
+	assert.Equal(t, a.root.Data, b.root.Data)
+}
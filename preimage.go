@@ -0,0 +1,104 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// Opening reveals the preimage of a leaf together with its Merkle path, so a
+// verifier can check both the leaf hashing and its inclusion.
+type Opening struct {
+	Index    int               // Index of the opened leaf.
+	Preimage []*big.Int        // Inputs whose Poseidon hash is the leaf.
+	Path     []*MerklePathItem // Merkle path of the leaf.
+}
+
+// InsertPreimage inserts the Poseidon hash of preimage at index and records
+// the preimage so the leaf can later be opened. It returns the leaf hash.
+// The preimage is dropped when the leaf is overwritten by a plain Insert.
+func (smt *SparseMerkleTree) InsertPreimage(index int, preimage []*big.Int) (*big.Int, error) {
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	if err := checkElements(preimage); err != nil {
+		return nil, err
+	}
+	leaf, err := poseidon.Hash(preimage)
+	if err != nil {
+		return nil, fmt.Errorf("cannot hash preimage: %w", err)
+	}
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	key := getPaddedBinaryString(index, smt.depth)
+	if err := smt.set(key, leaf); err != nil {
+		return nil, err
+	}
+	if smt.preimages == nil {
+		smt.preimages = make(map[string][]*big.Int)
+	}
+	smt.preimages[key] = append([]*big.Int(nil), preimage...)
+	return leaf, nil
+}
+
+// Open returns the recorded preimage and Merkle path of the leaf at index.
+func (smt *SparseMerkleTree) Open(index int) (*Opening, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	preimage, exists := smt.preimages[key]
+	if !exists {
+		return nil, fmt.Errorf("no preimage recorded at key: %s", key)
+	}
+
+	return &Opening{
+		Index:    index,
+		Preimage: append([]*big.Int(nil), preimage...),
+		Path:     smt.generateMerklePath(smt.root, key),
+	}, nil
+}
+
+// preimage returns a copy of the preimage recorded for the leaf at index.
+func (smt *SparseMerkleTree) preimage(index int) ([]*big.Int, bool) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if checkIndex(index, smt.depth) != nil {
+		return nil, false
+	}
+	preimage, exists := smt.preimages[getPaddedBinaryString(index, smt.depth)]
+	if !exists {
+		return nil, false
+	}
+	return append([]*big.Int(nil), preimage...), true
+}
+
+// VerifyOpening verifies that the opening's preimage hashes to a leaf included
+// at the opening's index under root.
+func VerifyOpening(opening *Opening, root *big.Int) bool {
+	if opening == nil || checkElements(opening.Preimage) != nil {
+		return false
+	}
+	leaf, err := poseidon.Hash(opening.Preimage)
+	if err != nil || !pathMatchesIndex(opening.Path, opening.Index) {
+		return false
+	}
+	return VerifyMerklePath(leaf, opening.Path, root)
+}
+
+// checkElements returns ErrMalformedInput if a preimage element is nil.
+func checkElements(elements []*big.Int) error {
+	for i, element := range elements {
+		if element == nil {
+			return fmt.Errorf("%w: nil preimage element %d", ErrMalformedInput, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	preimage := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+
+	leaf, err := smt.InsertPreimage(5, preimage)
+	assert.NoError(t, err)
+	assert.Equal(t, leaf, smt.leaves[getPaddedBinaryString(5, smt.depth)])
+
+	opening, err := smt.Open(5)
+	assert.NoError(t, err)
+	assert.Equal(t, preimage, opening.Preimage)
+	assert.True(t, VerifyOpening(opening, smt.root.Data))
+
+	opening.Preimage[0] = big.NewInt(9)
+	assert.False(t, VerifyOpening(opening, smt.root.Data))
+
+	smt.Insert(5, big.NewInt(7))
+	_, err = smt.Open(5)
+	assert.Error(t, err, "overwriting the leaf drops its preimage")
+
+	_, err = smt.InsertPreimage(8, preimage)
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package smt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// RootPublisher is notified of the head of every commit of a tree it is
+// registered with. Publishers are called synchronously under the tree's
+// lock, in commit order, so they must return quickly and must not call back
+// into the tree; wrap slow publishers with NewAsyncPublisher.
+type RootPublisher interface {
+	PublishRoot(head TreeHead) error
+}
+
+// RootPublisherFunc adapts a function, such as one submitting the root in a
+// transaction, to a RootPublisher.
+type RootPublisherFunc func(head TreeHead) error
+
+// PublishRoot implements RootPublisher.
+func (f RootPublisherFunc) PublishRoot(head TreeHead) error {
+	return f(head)
+}
+
+// registeredPublisher is a publisher with its error handler.
+type registeredPublisher struct {
+	publisher RootPublisher
+	onError   func(TreeHead, error)
+}
+
+// AddRootPublisher registers p to be called with the head of every
+// subsequent commit. A commit cannot be undone once published, so errors
+// returned by p are passed to onError, which may be nil.
+func (smt *SparseMerkleTree) AddRootPublisher(p RootPublisher, onError func(TreeHead, error)) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	smt.publishers = append(smt.publishers, registeredPublisher{publisher: p, onError: onError})
+}
+
+// publish calls every registered publisher with head. The caller must hold
+// the write lock.
+func (smt *SparseMerkleTree) publish(head TreeHead) {
+	for _, r := range smt.publishers {
+		if err := r.publisher.PublishRoot(head); err != nil && r.onError != nil {
+			r.onError(head, err)
+		}
+	}
+}
+
+// NewLogPublisher returns a publisher that logs every root to logger.
+func NewLogPublisher(logger *log.Logger) RootPublisher {
+	return RootPublisherFunc(func(head TreeHead) error {
+		logger.Printf("smt: version %d root %s", head.Version, head.Root)
+		return nil
+	})
+}
+
+// NewWebhookPublisher returns a publisher that POSTs every head as JSON to
+// url. A nil client uses http.DefaultClient. It blocks for the duration of
+// the request, so it is usually wrapped with NewAsyncPublisher.
+func NewWebhookPublisher(url string, client *http.Client) RootPublisher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return RootPublisherFunc(func(head TreeHead) error {
+		body, err := json.Marshal(head)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("webhook %s returned status %d", url, resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// ErrPublisherBacklog is returned by an async publisher whose buffer is full.
+var ErrPublisherBacklog = errors.New("publisher backlog full")
+
+// AsyncPublisher forwards heads to another publisher on its own goroutine,
+// so that slow publishers do not hold up commits.
+type AsyncPublisher struct {
+	next    RootPublisher
+	onError func(TreeHead, error)
+	heads   chan TreeHead
+	once    sync.Once
+	done    chan struct{}
+}
+
+// NewAsyncPublisher returns a publisher buffering up to buffer heads for next.
+// Errors returned by next are passed to onError, which may be nil.
+func NewAsyncPublisher(next RootPublisher, buffer int, onError func(TreeHead, error)) *AsyncPublisher {
+	p := &AsyncPublisher{next: next, onError: onError, heads: make(chan TreeHead, buffer), done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		for head := range p.heads {
+			if err := p.next.PublishRoot(head); err != nil && p.onError != nil {
+				p.onError(head, err)
+			}
+		}
+	}()
+	return p
+}
+
+// PublishRoot queues head for the wrapped publisher, returning
+// ErrPublisherBacklog instead of blocking when the buffer is full.
+func (p *AsyncPublisher) PublishRoot(head TreeHead) error {
+	select {
+	case p.heads <- head:
+		return nil
+	default:
+		return fmt.Errorf("%w: dropped version %d", ErrPublisherBacklog, head.Version)
+	}
+}
+
+// Close waits until every queued head has been published. The publisher
+// must not be used afterwards.
+func (p *AsyncPublisher) Close() {
+	p.once.Do(func() { close(p.heads) })
+	<-p.done
+}
@@ -0,0 +1,96 @@
+package smt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootPublisher(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+
+	var published []TreeHead
+	smt.AddRootPublisher(RootPublisherFunc(func(head TreeHead) error {
+		published = append(published, head)
+		return nil
+	}), nil)
+
+	var failures int
+	smt.AddRootPublisher(RootPublisherFunc(func(head TreeHead) error {
+		return errors.New("submitter unavailable")
+	}), func(head TreeHead, err error) { failures++ })
+
+	var buf bytes.Buffer
+	smt.AddRootPublisher(NewLogPublisher(log.New(&buf, "", 0)), nil)
+
+	smt.Insert(1, big.NewInt(1))
+	smt.Insert(2, big.NewInt(2))
+
+	assert.Len(t, published, 2)
+	assert.Equal(t, 2, published[1].Version)
+	assert.Equal(t, smt.root.Data, published[1].Root)
+	assert.Equal(t, 2, failures)
+	assert.Contains(t, buf.String(), "version 2 root "+smt.root.Data.String())
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	var mu sync.Mutex
+	var received []TreeHead
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var head TreeHead
+		if err := json.NewDecoder(r.Body).Decode(&head); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, head)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	async := NewAsyncPublisher(NewWebhookPublisher(server.URL, nil), 8, func(head TreeHead, err error) {
+		t.Error(err)
+	})
+	smt.AddRootPublisher(async, nil)
+
+	smt.Insert(1, big.NewInt(1))
+	smt.Insert(2, big.NewInt(2))
+	async.Close()
+
+	assert.Len(t, received, 2)
+	assert.Equal(t, 0, smt.root.Data.Cmp(received[1].Root))
+
+	err := NewWebhookPublisher(server.URL+"/fail", server.Client()).PublishRoot(TreeHead{})
+	assert.Error(t, err)
+}
+
+func TestAsyncPublisherBacklog(t *testing.T) {
+	block := make(chan struct{})
+	async := NewAsyncPublisher(RootPublisherFunc(func(head TreeHead) error {
+		<-block
+		return nil
+	}), 1, nil)
+
+	assert.NoError(t, async.PublishRoot(TreeHead{Version: 1}))
+	var err error
+	for i := 2; i < 5 && err == nil; i++ {
+		err = async.PublishRoot(TreeHead{Version: i})
+	}
+	assert.ErrorIs(t, err, ErrPublisherBacklog)
+
+	close(block)
+	async.Close()
+}
@@ -0,0 +1,248 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueClosed is returned when submitting to a closed write queue.
+	ErrQueueClosed = errors.New("write queue closed")
+	// ErrBatchTooLarge is returned when submitting more mutations than the queue accepts in one batch.
+	ErrBatchTooLarge = errors.New("batch too large")
+	// ErrQueueFull is returned when submitting to a queue that already holds its maximum of pending writes.
+	ErrQueueFull = errors.New("write queue full")
+)
+
+// Priority is the class of a queued write. Lower values are more urgent.
+type Priority int
+
+// Priority classes of queued writes.
+const (
+	PriorityHigh   Priority = iota // Latency-sensitive writes, such as user requests.
+	PriorityNormal                 // Regular writes.
+	PriorityBulk                   // Background writes, such as bulk migrations.
+
+	numPriorities = 3
+)
+
+// OrderingPolicy decides which queued write is applied next.
+type OrderingPolicy int
+
+// Ordering policies of a write queue.
+const (
+	OrderFIFO     OrderingPolicy = iota // Apply writes in submission order regardless of class.
+	OrderPriority                       // Apply the most urgent class first, in submission order within a class.
+)
+
+// WriteQueueConfig configures a write queue.
+type WriteQueueConfig struct {
+	Policy       OrderingPolicy // Order in which queued writes are applied.
+	MaxBatchSize int            // Largest number of mutations in a single write, or 0 for no limit.
+	MaxPending   int            // Largest number of writes waiting to be applied, or 0 for no limit.
+}
+
+// WriteResult is the outcome of a queued write.
+type WriteResult struct {
+	Root *big.Int // Root of the tree after the write, or nil on error.
+	Err  error    // Error returned by ApplyAtomic, if any.
+}
+
+// ClassStats holds latency metrics for one priority class. Latency is
+// measured from submission until the write has been applied.
+type ClassStats struct {
+	Applied      int           // Number of writes applied.
+	TotalLatency time.Duration // Sum of the latencies of applied writes.
+	MaxLatency   time.Duration // Largest latency of an applied write.
+}
+
+// MeanLatency returns the average latency of applied writes.
+func (s ClassStats) MeanLatency() time.Duration {
+	if s.Applied == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Applied)
+}
+
+// QueueMetrics holds backpressure metrics for a write queue.
+type QueueMetrics struct {
+	Depth            int // Number of writes waiting to be applied.
+	RejectedTooLarge int // Number of writes rejected with ErrBatchTooLarge.
+	RejectedFull     int // Number of writes rejected with ErrQueueFull.
+}
+
+// Rejected returns the total number of writes rejected for backpressure.
+func (m QueueMetrics) Rejected() int {
+	return m.RejectedTooLarge + m.RejectedFull
+}
+
+// queuedWrite is a batch of mutations waiting in a write queue.
+type queuedWrite struct {
+	mutations []Mutation
+	seq       uint64
+	submitted time.Time
+	result    chan WriteResult
+}
+
+// WriteQueue serializes writes to a tree through a single applier goroutine,
+// ordering them according to its policy.
+type WriteQueue struct {
+	tree   *SparseMerkleTree
+	config WriteQueueConfig
+
+	mu      sync.Mutex
+	ready   *sync.Cond
+	pending [numPriorities][]*queuedWrite
+	seq     uint64
+	closed  bool
+	stats   [numPriorities]ClassStats
+	metrics QueueMetrics
+	done    chan struct{}
+}
+
+// NewWriteQueue creates a write queue for tree and starts its applier.
+func NewWriteQueue(tree *SparseMerkleTree, config WriteQueueConfig) *WriteQueue {
+	q := &WriteQueue{tree: tree, config: config, done: make(chan struct{})}
+	q.ready = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// Enqueue queues mutations to be applied atomically in the given class and
+// returns a channel that receives the result once they have been applied.
+// It returns ErrBatchTooLarge or ErrQueueFull when the write exceeds the
+// limits of the queue, so producers can back off.
+func (q *WriteQueue) Enqueue(class Priority, mutations []Mutation) (<-chan WriteResult, error) {
+	if class < 0 || class >= numPriorities {
+		return nil, fmt.Errorf("unknown priority class: %d", class)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	if q.config.MaxBatchSize > 0 && len(mutations) > q.config.MaxBatchSize {
+		q.metrics.RejectedTooLarge++
+		return nil, fmt.Errorf("%w: %d mutations, limit %d", ErrBatchTooLarge, len(mutations), q.config.MaxBatchSize)
+	}
+	if q.config.MaxPending > 0 && q.metrics.Depth >= q.config.MaxPending {
+		q.metrics.RejectedFull++
+		return nil, fmt.Errorf("%w: %d writes pending", ErrQueueFull, q.metrics.Depth)
+	}
+
+	w := &queuedWrite{mutations: mutations, seq: q.seq, submitted: time.Now(), result: make(chan WriteResult, 1)}
+	q.seq++
+	q.pending[class] = append(q.pending[class], w)
+	q.metrics.Depth++
+	q.ready.Signal()
+	return w.result, nil
+}
+
+// Submit queues mutations in the given class and waits until they have been
+// applied, returning the resulting root.
+func (q *WriteQueue) Submit(class Priority, mutations []Mutation) (*big.Int, error) {
+	result, err := q.Enqueue(class, mutations)
+	if err != nil {
+		return nil, err
+	}
+	r := <-result
+	return r.Root, r.Err
+}
+
+// Stats returns the latency metrics of the given class.
+func (q *WriteQueue) Stats(class Priority) ClassStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if class < 0 || class >= numPriorities {
+		return ClassStats{}
+	}
+	return q.stats[class]
+}
+
+// Metrics returns the backpressure metrics of the queue.
+func (q *WriteQueue) Metrics() QueueMetrics {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.metrics
+}
+
+// Close stops accepting writes and waits until every queued write has been
+// applied.
+func (q *WriteQueue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		q.ready.Broadcast()
+	}
+	q.mu.Unlock()
+	<-q.done
+}
+
+// run applies queued writes one at a time until the queue is closed and drained.
+func (q *WriteQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		class, w := q.next()
+		for w == nil && !q.closed {
+			q.ready.Wait()
+			class, w = q.next()
+		}
+		q.mu.Unlock()
+
+		if w == nil {
+			return
+		}
+
+		root, err := q.tree.ApplyAtomic(w.mutations)
+		latency := time.Since(w.submitted)
+
+		q.mu.Lock()
+		stats := &q.stats[class]
+		stats.Applied++
+		stats.TotalLatency += latency
+		if latency > stats.MaxLatency {
+			stats.MaxLatency = latency
+		}
+		q.mu.Unlock()
+
+		w.result <- WriteResult{Root: root, Err: err}
+	}
+}
+
+// next removes and returns the write to apply next, or nil if none is queued.
+// The caller must hold q.mu.
+func (q *WriteQueue) next() (Priority, *queuedWrite) {
+	chosen := Priority(-1)
+	for class := Priority(0); class < numPriorities; class++ {
+		if len(q.pending[class]) == 0 {
+			continue
+		}
+		if chosen < 0 {
+			chosen = class
+			if q.config.Policy == OrderPriority {
+				break
+			}
+			continue
+		}
+		if q.pending[class][0].seq < q.pending[chosen][0].seq {
+			chosen = class
+		}
+	}
+	if chosen < 0 {
+		return 0, nil
+	}
+
+	w := q.pending[chosen][0]
+	q.pending[chosen] = q.pending[chosen][1:]
+	q.metrics.Depth--
+	return chosen, w
+}
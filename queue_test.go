@@ -0,0 +1,78 @@
+package smt
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteQueue(t *testing.T) {
+	tree := NewSparseMerkleTree(3, zeroLeaf)
+	q := NewWriteQueue(tree, WriteQueueConfig{Policy: OrderFIFO})
+
+	root, err := q.Submit(PriorityNormal, []Mutation{{Index: 1, Value: big.NewInt(1)}})
+	assert.NoError(t, err)
+	assert.Equal(t, tree.root.Data, root)
+
+	_, err = q.Submit(PriorityBulk, []Mutation{{Index: 9, Value: big.NewInt(1)}})
+	assert.Error(t, err)
+
+	_, err = q.Enqueue(Priority(7), nil)
+	assert.Error(t, err)
+
+	stats := q.Stats(PriorityNormal)
+	assert.Equal(t, 1, stats.Applied)
+	assert.GreaterOrEqual(t, stats.MaxLatency, stats.MeanLatency())
+
+	q.Close()
+	_, err = q.Enqueue(PriorityHigh, nil)
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestWriteQueueOrdering(t *testing.T) {
+	for _, tc := range []struct {
+		policy   OrderingPolicy
+		expected int64
+	}{
+		{OrderFIFO, 1},
+		{OrderPriority, 2},
+	} {
+		q := &WriteQueue{config: WriteQueueConfig{Policy: tc.policy}}
+		for i, class := range []Priority{PriorityBulk, PriorityHigh} {
+			q.pending[class] = append(q.pending[class], &queuedWrite{
+				mutations: []Mutation{{Index: 0, Value: big.NewInt(int64(i + 1))}},
+				seq:       uint64(i),
+			})
+		}
+
+		_, first := q.next()
+		_, second := q.next()
+		_, none := q.next()
+		assert.Equal(t, tc.expected, first.mutations[0].Value.Int64())
+		assert.NotNil(t, second)
+		assert.Nil(t, none)
+	}
+}
+
+func TestWriteQueueBackpressure(t *testing.T) {
+	// No applier runs, so enqueued writes stay pending.
+	q := &WriteQueue{config: WriteQueueConfig{MaxBatchSize: 2, MaxPending: 1}}
+	q.ready = sync.NewCond(&q.mu)
+
+	_, err := q.Enqueue(PriorityNormal, make([]Mutation, 3))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+
+	_, err = q.Enqueue(PriorityNormal, make([]Mutation, 2))
+	assert.NoError(t, err)
+	_, err = q.Enqueue(PriorityHigh, nil)
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	metrics := q.Metrics()
+	assert.Equal(t, QueueMetrics{Depth: 1, RejectedTooLarge: 1, RejectedFull: 1}, metrics)
+	assert.Equal(t, 2, metrics.Rejected())
+
+	q.next()
+	assert.Equal(t, 0, q.Metrics().Depth)
+}
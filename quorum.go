@@ -0,0 +1,143 @@
+package smt
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// HeadSource reports the current tree head of a replica.
+type HeadSource interface {
+	FetchHead(ctx context.Context) (TreeHead, error)
+}
+
+// HeadSourceFunc adapts a function to a HeadSource.
+type HeadSourceFunc func(ctx context.Context) (TreeHead, error)
+
+// FetchHead implements HeadSource.
+func (f HeadSourceFunc) FetchHead(ctx context.Context) (TreeHead, error) {
+	return f(ctx)
+}
+
+// FetchHead implements HeadSource for a local tree.
+func (smt *SparseMerkleTree) FetchHead(ctx context.Context) (TreeHead, error) {
+	return smt.Head(), nil
+}
+
+// QuorumReport is the outcome of comparing the heads of a set of replicas.
+type QuorumReport struct {
+	Root        *big.Int         // Root reported by a strict majority of replicas, or nil without a majority.
+	Version     int              // Highest version reported with the majority root.
+	Agreeing    []string         // Replicas reporting the majority root.
+	Lagging     []string         // Replicas reporting another root at a lower version than the majority.
+	Divergent   []string         // Replicas reporting another root at the same or a higher version.
+	Unreachable map[string]error // Replicas whose head could not be fetched.
+}
+
+// HasQuorum reports whether a strict majority of replicas agreed on a root.
+func (r *QuorumReport) HasQuorum() bool {
+	return r.Root != nil
+}
+
+// CheckQuorum fetches the heads of all replicas concurrently and reports
+// which of them agree with the majority root. Unreachable replicas count
+// against the majority. The report only names divergent replicas; call
+// DiffDivergent to find the leaves on which they differ.
+func CheckQuorum(ctx context.Context, replicas map[string]HeadSource) *QuorumReport {
+	type result struct {
+		head TreeHead
+		err  error
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]result, len(replicas))
+	for name, source := range replicas {
+		wg.Add(1)
+		go func(name string, source HeadSource) {
+			defer wg.Done()
+			head, err := source.FetchHead(ctx)
+			mu.Lock()
+			results[name] = result{head: head, err: err}
+			mu.Unlock()
+		}(name, source)
+	}
+	wg.Wait()
+
+	report := &QuorumReport{Unreachable: make(map[string]error)}
+	votes := make(map[string]int)
+	for name, r := range results {
+		if r.err != nil {
+			report.Unreachable[name] = r.err
+			continue
+		}
+		if r.head.Root == nil {
+			continue
+		}
+		votes[r.head.Root.String()]++
+	}
+
+	for root, count := range votes {
+		if 2*count > len(replicas) {
+			report.Root, _ = new(big.Int).SetString(root, 10)
+		}
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if report.Root != nil {
+		for _, name := range names {
+			if head := results[name].head; results[name].err == nil && head.Root != nil && head.Root.Cmp(report.Root) == 0 && head.Version > report.Version {
+				report.Version = head.Version
+			}
+		}
+	}
+
+	for _, name := range names {
+		r := results[name]
+		switch {
+		case r.err != nil:
+		case report.Root != nil && r.head.Root != nil && r.head.Root.Cmp(report.Root) == 0:
+			report.Agreeing = append(report.Agreeing, name)
+		case report.Root != nil && r.head.Version < report.Version:
+			report.Lagging = append(report.Lagging, name)
+		default:
+			report.Divergent = append(report.Divergent, name)
+		}
+	}
+
+	return report
+}
+
+// DiffDivergent runs Diff between reference, a tree at the majority root, and
+// every divergent replica whose HeadSource also implements ReconcilePeer,
+// returning the differing leaves by replica name. Divergent replicas that
+// cannot serve subtree hashes are left out of the result.
+func (r *QuorumReport) DiffDivergent(reference *SparseMerkleTree, replicas map[string]HeadSource) (map[string][]LeafDiff, error) {
+	if !r.HasQuorum() {
+		return nil, fmt.Errorf("no majority root to diff against")
+	}
+	if root := reference.Root(); root.Cmp(r.Root) != 0 {
+		return nil, fmt.Errorf("reference root %s differs from majority root %s", root, r.Root)
+	}
+
+	diffs := make(map[string][]LeafDiff, len(r.Divergent))
+	for _, name := range r.Divergent {
+		peer, ok := replicas[name].(ReconcilePeer)
+		if !ok {
+			continue
+		}
+		diff, err := reference.Diff(peer)
+		if err != nil {
+			return nil, fmt.Errorf("diff replica %s: %w", name, err)
+		}
+		diffs[name] = diff
+	}
+	return diffs, nil
+}
@@ -0,0 +1,55 @@
+package smt
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckQuorum(t *testing.T) {
+	newReplica := func(values ...int64) *SparseMerkleTree {
+		tree := NewSparseMerkleTree(3, zeroLeaf)
+		for i, value := range values {
+			tree.Insert(i, big.NewInt(value))
+		}
+		return tree
+	}
+
+	a, b := newReplica(1, 2), newReplica(1, 2)
+	replicas := map[string]HeadSource{
+		"a":       a,
+		"b":       b,
+		"lagging": newReplica(1),
+		"bad":     newReplica(1, 3),
+		"down": HeadSourceFunc(func(ctx context.Context) (TreeHead, error) {
+			return TreeHead{}, errors.New("connection refused")
+		}),
+	}
+
+	report := CheckQuorum(context.Background(), replicas)
+	assert.False(t, report.HasQuorum(), "two of five replicas are not a majority")
+	assert.Equal(t, []string{"a", "b", "bad", "lagging"}, report.Divergent)
+	assert.Contains(t, report.Unreachable, "down")
+
+	replicas["c"] = newReplica(1, 2)
+	replicas["d"] = newReplica(1, 2)
+	report = CheckQuorum(context.Background(), replicas)
+	assert.True(t, report.HasQuorum())
+	assert.Equal(t, a.root.Data, report.Root)
+	assert.Equal(t, 2, report.Version)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, report.Agreeing)
+	assert.Equal(t, []string{"lagging"}, report.Lagging)
+	assert.Equal(t, []string{"bad"}, report.Divergent)
+
+	diffs, err := report.DiffDivergent(a, replicas)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]LeafDiff{
+		"bad": {{Index: big.NewInt(1), Local: big.NewInt(2), Remote: big.NewInt(3)}},
+	}, diffs)
+
+	_, err = report.DiffDivergent(newReplica(1), replicas)
+	assert.Error(t, err, "the reference must be at the majority root")
+}
@@ -0,0 +1,59 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RangeProof proves the values of the contiguous leaves from Start on. The
+// subtrees inside the range are computed from the leaves, so at most two
+// siblings per level, those at the edges of the range, are carried.
+type RangeProof struct {
+	Depth    int        `json:"depth"`    // Depth of the tree.
+	Start    int        `json:"start"`    // Index of the first proven leaf.
+	Leaves   []*big.Int `json:"leaves"`   // Values of the leaves from Start on, the zero leaf if unset.
+	Siblings []*big.Int `json:"siblings"` // Sibling hashes at the edges of the range, from the leaves up.
+}
+
+// GenerateRangeProof generates one proof of the leaves from start to end
+// inclusive, set or not.
+func (smt *SparseMerkleTree) GenerateRangeProof(start, end int) (*RangeProof, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range: %d to %d", start, end)
+	}
+	if err := checkIndex(start, smt.depth); err != nil {
+		return nil, err
+	}
+	if err := checkIndex(end, smt.depth); err != nil {
+		return nil, err
+	}
+
+	indices := make([]int, 0, end-start+1)
+	for index := start; index <= end; index++ {
+		indices = append(indices, index)
+	}
+	multi, err := smt.GenerateMultiProof(indices)
+	if err != nil {
+		return nil, err
+	}
+	return &RangeProof{Depth: multi.Depth, Start: start, Leaves: multi.Leaves, Siblings: multi.Siblings}, nil
+}
+
+// End returns the index of the last leaf proven by the proof.
+func (proof *RangeProof) End() int {
+	return proof.Start + len(proof.Leaves) - 1
+}
+
+// VerifyRangeProof verifies a range proof against the expected root of a
+// Poseidon tree. The caller compares the proven leaves with the values it
+// expects.
+func VerifyRangeProof(proof *RangeProof, expectedRoot *big.Int) bool {
+	if proof == nil || len(proof.Leaves) == 0 || checkIndex(proof.Start, proof.Depth) != nil || checkIndex(proof.End(), proof.Depth) != nil {
+		return false
+	}
+	multi := &MultiProof{Depth: proof.Depth, Indices: make([]int, len(proof.Leaves)), Leaves: proof.Leaves, Siblings: proof.Siblings}
+	for i := range multi.Indices {
+		multi.Indices[i] = proof.Start + i
+	}
+	return VerifyMultiProof(multi, expectedRoot)
+}
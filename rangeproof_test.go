@@ -0,0 +1,40 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeProof(t *testing.T) {
+	smt := NewSparseMerkleTree(5, zeroLeaf)
+	for index := 3; index < 20; index += 2 {
+		assert.NoError(t, smt.Insert(index, big.NewInt(int64(index))))
+	}
+
+	proof, err := smt.GenerateRangeProof(3, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, proof.End())
+	assert.Len(t, proof.Leaves, 10)
+	assert.Equal(t, big.NewInt(5), proof.Leaves[2])
+	assert.Equal(t, zeroLeaf, proof.Leaves[3])
+	assert.LessOrEqual(t, len(proof.Siblings), 2*5)
+	assert.True(t, VerifyRangeProof(proof, smt.Root()))
+
+	proof.Leaves[3] = big.NewInt(6)
+	assert.False(t, VerifyRangeProof(proof, smt.Root()))
+	proof.Leaves[3] = zeroLeaf
+	proof.Start++
+	assert.False(t, VerifyRangeProof(proof, smt.Root()), "the range is bound to its start")
+
+	single, err := smt.GenerateRangeProof(31, 31)
+	assert.NoError(t, err)
+	assert.True(t, VerifyRangeProof(single, smt.Root()))
+
+	_, err = smt.GenerateRangeProof(5, 4)
+	assert.Error(t, err)
+	_, err = smt.GenerateRangeProof(0, 32)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	assert.False(t, VerifyRangeProof(&RangeProof{Depth: 5}, smt.Root()))
+}
@@ -0,0 +1,95 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Receipt describes the cost of a single operation on a tree, for cost models
+// and gas estimators that need measured rather than estimated figures.
+type Receipt struct {
+	Version        int           `json:"version"`        // Version the operation produced, or read if it did not commit.
+	Hashes         int           `json:"hashes"`         // Number of node hashes the operation computed.
+	HashArity      int           `json:"hashArity"`      // Number of inputs of every node hash, NodeHashArity.
+	NodesWritten   int           `json:"nodesWritten"`   // Number of nodes the operation wrote.
+	NodesRead      int           `json:"nodesRead"`      // Number of stored nodes a proof read its siblings from.
+	VerifierHashes int           `json:"verifierHashes"` // Number of node hashes a verifier of the proof computes.
+	Duration       time.Duration `json:"duration"`       // Time the operation took, including waiting for the lock.
+}
+
+// InsertWithReceipt is Insert returning a receipt of the hashes and node
+// writes of the commit it produced.
+func (smt *SparseMerkleTree) InsertWithReceipt(index int, value *big.Int) (*Receipt, error) {
+	start := time.Now()
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	if err := smt.set(getPaddedBinaryString(index, smt.depth), value); err != nil {
+		return nil, err
+	}
+	return smt.commitReceipt(start), nil
+}
+
+// DeleteWithReceipt is Delete returning a receipt of the hashes and node
+// writes of the commit it produced. Deleting an unset leaf or a tombstone
+// does not commit, and its receipt counts nothing.
+func (smt *SparseMerkleTree) DeleteWithReceipt(index int) (*Receipt, error) {
+	start := time.Now()
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	version := smt.version
+	if err := smt.deleteKey(getPaddedBinaryString(index, smt.depth)); err != nil {
+		return nil, err
+	}
+	if smt.version == version {
+		return &Receipt{Version: version, HashArity: NodeHashArity, Duration: time.Since(start)}, nil
+	}
+	return smt.commitReceipt(start), nil
+}
+
+// ProveWithReceipt is GenerateMerklePath returning a receipt of the nodes the
+// path was read from and the hashes a verifier computes. Generating a path
+// computes no hashes.
+func (smt *SparseMerkleTree) ProveWithReceipt(index int) ([]*MerklePathItem, *Receipt, error) {
+	start := time.Now()
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	if _, exists := smt.leaves[key]; !exists {
+		return nil, nil, fmt.Errorf("no leaf exists at key: %s", key)
+	}
+	path := smt.generateMerklePath(smt.root, key)
+	stats := smt.proofStats(path)
+	return path, &Receipt{
+		Version:        smt.version,
+		HashArity:      NodeHashArity,
+		NodesRead:      stats.Siblings - stats.EmptySiblings,
+		VerifierHashes: stats.VerifierHashes,
+		Duration:       time.Since(start),
+	}, nil
+}
+
+// commitReceipt returns the receipt of the operation that started at start
+// and produced the latest commit. The caller must hold the write lock.
+func (smt *SparseMerkleTree) commitReceipt(start time.Time) *Receipt {
+	stats := smt.commitStats[len(smt.commitStats)-1]
+	return &Receipt{
+		Version:      stats.Version,
+		Hashes:       stats.Hashes,
+		HashArity:    NodeHashArity,
+		NodesWritten: stats.NodesWritten,
+		Duration:     time.Since(start),
+	}
+}
@@ -0,0 +1,49 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceipts(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+
+	receipt, err := smt.InsertWithReceipt(0, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, receipt.Version)
+	assert.Equal(t, 3, receipt.Hashes, "one per inner node on the path")
+	assert.Equal(t, NodeHashArity, receipt.HashArity)
+	assert.Equal(t, 4, receipt.NodesWritten, "root to leaf in a depth-3 tree")
+	assert.Positive(t, receipt.Duration)
+
+	receipt, err = smt.InsertWithReceipt(1, big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, receipt.Version)
+	assert.Equal(t, 3, receipt.Hashes)
+
+	path, receipt, err := smt.ProveWithReceipt(0)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePath(big.NewInt(1), path, smt.Root()))
+	assert.Equal(t, 2, receipt.Version)
+	assert.Zero(t, receipt.Hashes, "generating a path computes no hashes")
+	assert.Equal(t, 1, receipt.NodesRead, "only the sibling leaf 001 is stored")
+	assert.Equal(t, 3, receipt.VerifierHashes)
+
+	receipt, err = smt.DeleteWithReceipt(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, receipt.Version)
+	assert.Equal(t, 3, receipt.Hashes)
+
+	receipt, err = smt.DeleteWithReceipt(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, receipt.Version, "deleting an unset leaf does not commit")
+	assert.Zero(t, receipt.Hashes)
+	assert.Zero(t, receipt.NodesWritten)
+
+	_, err = smt.InsertWithReceipt(8, big.NewInt(1))
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	_, _, err = smt.ProveWithReceipt(5)
+	assert.Error(t, err)
+}
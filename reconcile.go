@@ -0,0 +1,179 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ReconcilePeer is the remote side of an anti-entropy reconciliation. A
+// SparseMerkleTree implements it directly; remote replicas implement it over
+// their transport of choice, with one round trip per call.
+type ReconcilePeer interface {
+	// SubtreeHashes returns the hashes of the subtrees at the given binary prefixes.
+	SubtreeHashes(prefixes []string) ([]*big.Int, error)
+	// LeafValues returns the values of the leaves with the given binary keys, with nil for unset leaves.
+	LeafValues(keys []string) ([]*big.Int, error)
+}
+
+// LeafDiff is a leaf whose value differs between two replicas. A nil value
+// means the leaf is unset on that side. The index is a big integer so that
+// trees deeper than 62 levels can be compared.
+type LeafDiff struct {
+	Index  *big.Int // Index of the leaf.
+	Local  *big.Int // Value in the local tree.
+	Remote *big.Int // Value in the peer's tree.
+}
+
+// MergeFunc resolves a differing leaf, returning the value to store locally
+// and whether to store it at all.
+type MergeFunc func(diff LeafDiff) (*big.Int, bool)
+
+// MergeTakeRemote resolves every difference in favour of the peer's value.
+func MergeTakeRemote(diff LeafDiff) (*big.Int, bool) {
+	return diff.Remote, diff.Remote != nil
+}
+
+// MergeMax keeps the larger of the two values, which makes every leaf a
+// grow-only max register: replicas that merge each other converge regardless
+// of the order of merges.
+func MergeMax(diff LeafDiff) (*big.Int, bool) {
+	if diff.Remote == nil || (diff.Local != nil && diff.Local.Cmp(diff.Remote) >= 0) {
+		return nil, false
+	}
+	return diff.Remote, true
+}
+
+// SubtreeHashes implements ReconcilePeer.
+func (smt *SparseMerkleTree) SubtreeHashes(prefixes []string) ([]*big.Int, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	hashes := make([]*big.Int, len(prefixes))
+	for i, prefix := range prefixes {
+		if err := checkPrefix(prefix, smt.depth); err != nil {
+			return nil, err
+		}
+		hashes[i] = smt.prefixHash(prefix)
+	}
+	return hashes, nil
+}
+
+// LeafValues implements ReconcilePeer.
+func (smt *SparseMerkleTree) LeafValues(keys []string) ([]*big.Int, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	values := make([]*big.Int, len(keys))
+	for i, key := range keys {
+		if err := checkKey(key, smt.depth); err != nil {
+			return nil, err
+		}
+		values[i] = copyInt(smt.leaves[key])
+	}
+	return values, nil
+}
+
+// Diff finds the leaves that differ between the tree and peer, which must
+// have the same depth and zero leaf. Subtree hashes are compared level by
+// level, descending only into differing subtrees, so it takes one round trip
+// per level and exchanges O(diff * depth) hashes.
+func (smt *SparseMerkleTree) Diff(peer ReconcilePeer) ([]LeafDiff, error) {
+	return smt.diff(peer, nil)
+}
+
+// diff implements Diff, calling onSubtree, if set, with the local and remote
+// hashes of every differing subtree it visits.
+func (smt *SparseMerkleTree) diff(peer ReconcilePeer, onSubtree func(prefix string, local, remote *big.Int)) ([]LeafDiff, error) {
+	prefixes := []string{""}
+	for depth := 0; depth <= smt.depth && len(prefixes) > 0; depth++ {
+		local, err := smt.SubtreeHashes(prefixes)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := peer.SubtreeHashes(prefixes)
+		if err != nil {
+			return nil, err
+		}
+		if len(remote) != len(prefixes) {
+			return nil, fmt.Errorf("peer returned %d hashes for %d prefixes", len(remote), len(prefixes))
+		}
+
+		var differing []string
+		for i, prefix := range prefixes {
+			if remote[i] == nil || local[i].Cmp(remote[i]) != 0 {
+				differing = append(differing, prefix)
+				if onSubtree != nil {
+					onSubtree(prefix, local[i], remote[i])
+				}
+			}
+		}
+
+		if depth == smt.depth {
+			return smt.diffLeaves(peer, differing)
+		}
+		prefixes = prefixes[:0]
+		for _, prefix := range differing {
+			prefixes = append(prefixes, prefix+"0", prefix+"1")
+		}
+	}
+	return nil, nil
+}
+
+// diffLeaves fetches the local and remote values of the leaves with the given
+// keys.
+func (smt *SparseMerkleTree) diffLeaves(peer ReconcilePeer, keys []string) ([]LeafDiff, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	local, err := smt.LeafValues(keys)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := peer.LeafValues(keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(remote) != len(keys) {
+		return nil, fmt.Errorf("peer returned %d values for %d leaves", len(remote), len(keys))
+	}
+
+	diffs := make([]LeafDiff, len(keys))
+	for i, key := range keys {
+		diffs[i] = LeafDiff{Index: getBigIndexFromBinaryString(key), Local: local[i], Remote: remote[i]}
+	}
+	return diffs, nil
+}
+
+// Reconcile finds the leaves that differ from peer, resolves each with merge
+// and applies the chosen values as a single commit. It returns the
+// differences found.
+func (smt *SparseMerkleTree) Reconcile(peer ReconcilePeer, merge MergeFunc) ([]LeafDiff, error) {
+	diffs, err := smt.Diff(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]*big.Int)
+	for _, diff := range diffs {
+		if value, ok := merge(diff); ok {
+			if err := checkValue(value); err != nil {
+				return nil, fmt.Errorf("index %s: %w", diff.Index, err)
+			}
+			key, err := getBigPaddedBinaryString(diff.Index, smt.depth)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+	}
+	if len(values) > 0 {
+		smt.mu.Lock()
+		defer smt.mu.Unlock()
+
+		if err := smt.applyValues(values); err != nil {
+			return nil, err
+		}
+	}
+	return diffs, nil
+}
@@ -0,0 +1,79 @@
+package smt
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingPeer counts the hashes a peer is asked for.
+type countingPeer struct {
+	*SparseMerkleTree
+	hashes int
+}
+
+func (p *countingPeer) SubtreeHashes(prefixes []string) ([]*big.Int, error) {
+	p.hashes += len(prefixes)
+	return p.SparseMerkleTree.SubtreeHashes(prefixes)
+}
+
+func TestDiff(t *testing.T) {
+	a, b := NewSparseMerkleTree(8, zeroLeaf), NewSparseMerkleTree(8, zeroLeaf)
+	for i := 0; i < 256; i += 5 {
+		a.Insert(i, big.NewInt(int64(i)))
+		b.Insert(i, big.NewInt(int64(i)))
+	}
+	a.Insert(17, big.NewInt(1))
+	b.Insert(200, big.NewInt(2))
+
+	peer := &countingPeer{SparseMerkleTree: b}
+	diffs, err := a.Diff(peer)
+	assert.NoError(t, err)
+	assert.Equal(t, []LeafDiff{
+		{Index: big.NewInt(17), Local: big.NewInt(1)},
+		{Index: big.NewInt(200), Local: big.NewInt(200), Remote: big.NewInt(2)},
+	}, diffs)
+	assert.LessOrEqual(t, peer.hashes, 1+2*2*a.depth, "only differing subtrees are descended into")
+
+	diffs, err = a.Diff(a)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestReconcileConverges(t *testing.T) {
+	a, b := NewSparseMerkleTree(4, zeroLeaf), NewSparseMerkleTree(4, zeroLeaf)
+	a.Insert(1, big.NewInt(5))
+	a.Insert(2, big.NewInt(1))
+	b.Insert(1, big.NewInt(3))
+	b.Insert(2, big.NewInt(7))
+	b.Insert(9, big.NewInt(9))
+
+	_, err := a.Reconcile(b, MergeMax)
+	assert.NoError(t, err)
+	_, err = b.Reconcile(a, MergeMax)
+	assert.NoError(t, err)
+
+	assert.Equal(t, a.root.Data, b.root.Data)
+	assert.Equal(t, big.NewInt(5), a.leaves[getPaddedBinaryString(1, 4)])
+	assert.Equal(t, big.NewInt(7), a.leaves[getPaddedBinaryString(2, 4)])
+	assert.Equal(t, big.NewInt(9), a.leaves[getPaddedBinaryString(9, 4)])
+
+	c := NewSparseMerkleTree(4, zeroLeaf)
+	_, err = c.Reconcile(a, MergeTakeRemote)
+	assert.NoError(t, err)
+	assert.Equal(t, a.root.Data, c.root.Data)
+}
+
+func TestReconcileAddressTrees(t *testing.T) {
+	a, b := NewAddressTree(), NewAddressTree()
+	address := bytes.Repeat([]byte{0xfe}, 20)
+	assert.NoError(t, b.Insert(address, big.NewInt(1)))
+
+	diffs, err := a.Tree().Reconcile(b.Tree(), MergeTakeRemote)
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, new(big.Int).SetBytes(address), diffs[0].Index)
+	assert.Equal(t, b.Root(), a.Root())
+}
@@ -0,0 +1,28 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMalformedInput is returned when a proof, claim or witness is missing
+	// fields it cannot be checked without.
+	ErrMalformedInput = errors.New("malformed input")
+	// ErrPanic is returned by Recover when the function it runs panics.
+	ErrPanic = errors.New("recovered panic")
+)
+
+// Recover runs fn and converts a panic into an error wrapping ErrPanic, so a
+// server processing untrusted input answers a request that hits a bug with
+// an error instead of crashing. Verification functions do not panic on
+// malformed proofs; Recover guards against the cases that were missed and
+// against misuse such as constructing a tree with a nil zero leaf.
+func Recover(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,76 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	assert.NoError(t, Recover(func() error { return nil }))
+	err := Recover(func() error {
+		NewSparseMerkleTree(2, nil)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestMalformedInput(t *testing.T) {
+	one := big.NewInt(1)
+	assert.ErrorIs(t, Verify((*MembershipClaim)(nil), one, one), ErrMalformedInput)
+	assert.ErrorIs(t, Verify((*NonMembershipClaim)(nil), one, one), ErrMalformedInput)
+	assert.ErrorIs(t, Verify((*TransitionClaim)(nil), one, one), ErrMalformedInput)
+	assert.ErrorIs(t, VerifyContinuityProof(nil, one, one), ErrMalformedInput)
+	assert.ErrorIs(t, VerifyKeyHistory(nil, one), ErrMalformedInput)
+	assert.False(t, VerifyTransitionProof(nil))
+	assert.False(t, VerifyOpening(nil, one))
+	assert.False(t, VerifyOpening(&Opening{Preimage: []*big.Int{nil}}, one))
+
+	smt := NewSparseMerkleTree(2, zeroLeaf)
+	_, err := smt.InsertPreimage(0, []*big.Int{one, nil})
+	assert.ErrorIs(t, err, ErrMalformedInput)
+	_, err = smt.Add(0, nil)
+	assert.Error(t, err)
+	_, err = smt.Sub(0, nil)
+	assert.Error(t, err)
+
+	for _, w := range []*Witness{
+		nil,
+		{Depth: 2},
+		{Depth: 2, ZeroLeaf: zeroLeaf, Leaves: map[int]*big.Int{0: nil}},
+		{Depth: 2, ZeroLeaf: zeroLeaf, Leaves: map[int]*big.Int{4: one}},
+		{Depth: 2, ZeroLeaf: zeroLeaf, Nodes: map[string]*big.Int{"1": nil}},
+		{Depth: 2, ZeroLeaf: zeroLeaf, Nodes: map[string]*big.Int{"x": one}},
+	} {
+		_, err := ApplyOnWitness(w, nil)
+		assert.ErrorIs(t, err, ErrMalformedInput)
+	}
+	witness, err := smt.ExtractWitness([]int{0})
+	assert.NoError(t, err)
+	_, err = ApplyOnWitness(witness, map[int]*big.Int{0: nil})
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestMalformedIndex(t *testing.T) {
+	smt := NewSparseMerkleTree(2, zeroLeaf)
+	assert.NoError(t, smt.Insert(0, big.NewInt(1)))
+
+	for _, index := range []int{-1, 4, 1 << 10} {
+		_, err := smt.MembershipClaim(index)
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+		_, err = smt.Open(index)
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+		_, err = smt.GenerateMerklePath(index)
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+		_, err = smt.NewBundle([]int{index}, nil)
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	}
+
+	one := big.NewInt(1)
+	assert.False(t, VerifyIden3Proof(nil, one, one, one))
+	assert.False(t, VerifyIden3Proof(&Iden3Proof{}, one, nil, one))
+	assert.False(t, VerifyIden3Proof(&Iden3Proof{}, nil, one, one))
+	_, err := ExpandCompactMerklePath(0, nil, PoseidonHasher, zeroLeaf)
+	assert.ErrorIs(t, err, ErrMalformedInput)
+}
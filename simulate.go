@@ -0,0 +1,73 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// TransitionProof proves that setting one leaf moved the tree from OldRoot to
+// NewRoot. The siblings on the leaf's path are the same before and after the
+// update, so a single path serves both roots.
+type TransitionProof struct {
+	Index   int               // Index of the updated leaf.
+	OldLeaf *big.Int          // Value of the leaf before the update.
+	NewLeaf *big.Int          // Value of the leaf after the update.
+	OldRoot *big.Int          // Root of the tree before the update.
+	NewRoot *big.Int          // Root of the tree after the update.
+	Path    []*MerklePathItem // Merkle path of the leaf, shared by both roots.
+}
+
+// VerifyTransitionProof verifies that the proof's path is that of its index
+// and links the old leaf to the old root and the new leaf to the new root.
+func VerifyTransitionProof(proof *TransitionProof) bool {
+	if proof == nil || !pathMatchesIndex(proof.Path, proof.Index) {
+		return false
+	}
+	return VerifyMerklePath(proof.OldLeaf, proof.Path, proof.OldRoot) &&
+		VerifyMerklePath(proof.NewLeaf, proof.Path, proof.NewRoot)
+}
+
+// SimulateBatch computes the root the tree would have after applying updates
+// in order, together with a transition proof for every update, without
+// mutating the tree. Updated paths are copied and untouched subtrees are
+// shared with the live tree.
+func (smt *SparseMerkleTree) SimulateBatch(updates []Mutation) (*big.Int, []TransitionProof, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	for i, m := range updates {
+		if err := checkIndex(m.Index, smt.depth); err != nil {
+			return nil, nil, fmt.Errorf("update %d: %w", i, err)
+		}
+		if err := checkValue(m.Value); err != nil {
+			return nil, nil, fmt.Errorf("update %d: %w", i, err)
+		}
+	}
+
+	root := smt.root
+	leaves := make(map[string]*big.Int)
+	proofs := make([]TransitionProof, 0, len(updates))
+	for _, m := range updates {
+		key := getPaddedBinaryString(m.Index, smt.depth)
+		oldLeaf, updated := leaves[key]
+		if !updated {
+			oldLeaf = smt.leafOrZero(key)
+		}
+
+		path := smt.generateMerklePath(root, key)
+		newRoot := smt.insertWith(root, key, m.Value, 0, smt.nodeHash)
+		proofs = append(proofs, TransitionProof{
+			Index:   m.Index,
+			OldLeaf: oldLeaf,
+			NewLeaf: m.Value,
+			OldRoot: root.Data,
+			NewRoot: newRoot.Data,
+			Path:    path,
+		})
+
+		leaves[key] = m.Value
+		root = newRoot
+	}
+
+	return root.Data, proofs, nil
+}
@@ -0,0 +1,44 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateBatch(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(1, big.NewInt(10))
+	before := smt.root.Data
+
+	updates := []Mutation{
+		{Index: 1, Value: big.NewInt(11)},
+		{Index: 6, Value: big.NewInt(60)},
+		{Index: 1, Value: big.NewInt(12)},
+	}
+	root, proofs, err := smt.SimulateBatch(updates)
+	assert.NoError(t, err)
+	assert.Equal(t, before, smt.root.Data, "simulation must not mutate the tree")
+	assert.Len(t, smt.leaves, 1)
+
+	assert.Len(t, proofs, 3)
+	assert.Equal(t, before, proofs[0].OldRoot)
+	assert.Equal(t, big.NewInt(10), proofs[0].OldLeaf)
+	assert.Equal(t, zeroLeaf, proofs[1].OldLeaf)
+	assert.Equal(t, big.NewInt(11), proofs[2].OldLeaf)
+	for i := range proofs {
+		assert.True(t, VerifyTransitionProof(&proofs[i]))
+		if i > 0 {
+			assert.Equal(t, proofs[i-1].NewRoot, proofs[i].OldRoot)
+		}
+	}
+	assert.Equal(t, proofs[2].NewRoot, root)
+
+	_, err = smt.ApplyAtomic(updates)
+	assert.NoError(t, err)
+	assert.Equal(t, smt.root.Data, root)
+
+	_, _, err = smt.SimulateBatch([]Mutation{{Index: 8, Value: big.NewInt(1)}})
+	assert.Error(t, err)
+}
@@ -10,130 +10,497 @@ Merkle Tree.
 package smt
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/pycckuu/smt/verify"
 )
 
-// SparseMerkleTree represents a sparse Merkle tree.
+// ErrIndexOutOfRange is returned when an index does not address a leaf of the
+// tree.
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// ErrInvalidValue is returned when a leaf value is not a canonical field
+// element.
+var ErrInvalidValue = errors.New("invalid leaf value")
+
+// SparseMerkleTree represents a sparse Merkle tree. Its state is only
+// reachable through its methods, so callers cannot desynchronize the nodes,
+// leaves and hashes.
 type SparseMerkleTree struct {
-	Root     *MerkleNode         // The root node of the Sparse Merkle Tree.
-	Depth    int                 // The depth of the Sparse Merkle Tree.
-	Leaves   map[string]*big.Int // The leaves of the Sparse Merkle Tree, where keys are the binary representation of the index.
-	ZeroLeaf *big.Int            // Hash of the zero leaf.
-}
+	root     *MerkleNode         // The root node of the Sparse Merkle Tree.
+	depth    int                 // The depth of the Sparse Merkle Tree.
+	leaves   map[string]*big.Int // The leaves of the Sparse Merkle Tree, where keys are the binary representation of the index.
+	zeroLeaf *big.Int            // Hash of the zero leaf.
+	hasher   Hasher              // Hasher of the inner nodes.
+
+	compressed bool // Whether a subtree holding a single leaf is stored as one node, see compressedNode.
+
+	tombstone *big.Int // Leaf written by Delete in tombstone mode, or nil to remove deleted leaves.
+
+	nonDefaultLeaves int       // Number of leaves holding a value other than the zero leaf.
+	capacity         *capacity // Bound on nonDefaultLeaves, or nil for no limit.
+
+	emptyHashes []*big.Int // Hashes of empty subtrees, by height.
+
+	version     int         // Number of committed mutating operations.
+	committedAt time.Time   // Time of the last commit, or of creation for a new tree.
+	recentHeads []TreeHead  // Heads of the most recent commits, oldest first.
+	heights     []heightTag // Versions tagged with external heights, by increasing height.
 
-// MerklePathItem represents an item in the Merkle tree path.
-type MerklePathItem struct {
-	SiblingHash *big.Int // Hash of the sibling in the Merkle Path.
-	IsRight     bool     // Indicates whether this sibling node is a right child.
+	commitStats  []CommitStats       // Statistics of the most recent commits, oldest first.
+	pendingStats CommitStats         // Statistics of the commit in progress.
+	hashes       atomic.Int64        // Node hashes computed for the commit in progress, counted across hashing goroutines.
+	dirtyKeys    map[string]struct{} // Binary keys of the leaves changed by the commit in progress.
+
+	hashPool   *HashPool             // Pool bounding parallel hashing, or nil for the default pool.
+	publishers []registeredPublisher // Publishers notified of every commit.
+	watchers   []*leafWatcher        // Subscribers of WatchLeafWithProof.
+
+	mu         sync.RWMutex          // Guards the tree against concurrent use of its methods.
+	hashedKeys map[string]hashedKey  // Original identifiers of leaves inserted by hashed key, by binary index.
+	preimages  map[string][]*big.Int // Preimages of leaves inserted with InsertPreimage, by binary index.
+	keyCodec   KeyCodec              // Codec mapping application keys to indices, or nil for hashed keys.
+	keyNames   map[string]string     // Application keys of leaves inserted with InsertKey, by binary index.
 }
 
+// MerklePathItem represents an item in the Merkle tree path. It is defined in
+// package verify, so proofs can be checked without importing the tree.
+type MerklePathItem = verify.PathItem
+
 // MerkleNode represents an individual node in the Merkle Tree.
 type MerkleNode struct {
 	Left  *MerkleNode // Left child of the current node.
 	Right *MerkleNode // Right child of the current node.
 	Data  *big.Int    // Hash of the current node.
+
+	leafKey   string   // In a compressed tree, binary key of the only leaf below the node, which then has no children.
+	leafValue *big.Int // In a compressed tree, value of the only leaf below the node.
 }
 
 // NewSparseMerkleTree creates a new sparse Merkle tree with empty leaves.
 func NewSparseMerkleTree(depth int, zeroLeaf *big.Int) *SparseMerkleTree {
+	zeroLeaf = copyInt(zeroLeaf)
 	emptyLeaves := make(map[string]*big.Int)
-	root := &MerkleNode{Data: getHashEmptyForDepth(depth, zeroLeaf)}
-	return &SparseMerkleTree{Root: root, Depth: depth, Leaves: emptyLeaves, ZeroLeaf: zeroLeaf}
+	emptyHashes := getEmptyHashes(depth, zeroLeaf)
+	root := &MerkleNode{Data: emptyHashes[depth]}
+	return &SparseMerkleTree{root: root, depth: depth, leaves: emptyLeaves, zeroLeaf: zeroLeaf, hasher: PoseidonHasher, emptyHashes: emptyHashes, committedAt: time.Now()}
 }
 
-// Insert inserts a leaf with the given index and value into the tree.
-func (smt *SparseMerkleTree) Insert(index int, value *big.Int) {
-	key := getPaddedBinaryString(int(index), smt.Depth)
-	smt.Leaves[key] = value
-	smt.Root = smt.insertIntoNode(smt.Root, key, value, 0, smt.Depth)
+// Insert inserts a leaf with the given index and value into the tree. It
+// returns ErrIndexOutOfRange if index does not address a leaf of the tree.
+func (smt *SparseMerkleTree) Insert(index int, value *big.Int) error {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return err
+	}
+	return smt.set(getPaddedBinaryString(index, smt.depth), value)
 }
 
-// insertIntoNode inserts a leaf into the given node at the specified depth.
-func (smt *SparseMerkleTree) insertIntoNode(node *MerkleNode, key string, value *big.Int, depth, maxDepth int) *MerkleNode {
-	if node == nil {
-		node = &MerkleNode{Data: getHashEmptyForDepth(maxDepth-depth, smt.ZeroLeaf)}
+// Root returns the root hash of the tree.
+func (smt *SparseMerkleTree) Root() *big.Int {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return copyInt(smt.root.Data)
+}
+
+// Depth returns the depth of the tree.
+func (smt *SparseMerkleTree) Depth() int {
+	return smt.depth
+}
+
+// ZeroLeaf returns the hash of the zero leaf.
+func (smt *SparseMerkleTree) ZeroLeaf() *big.Int {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return copyInt(smt.zeroLeaf)
+}
+
+// Leaf returns the value of the leaf at index, which is the zero leaf if none
+// was inserted there.
+func (smt *SparseMerkleTree) Leaf(index int) (*big.Int, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	return copyInt(smt.leafOrZero(getPaddedBinaryString(index, smt.depth))), nil
+}
+
+// Update sets the leaf at index to value and returns its previous value and
+// the new root. An index that was never inserted previously held the zero
+// leaf.
+func (smt *SparseMerkleTree) Update(index int, value *big.Int) (old *big.Int, newRoot *big.Int, err error) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, nil, err
+	}
+
+	key := getPaddedBinaryString(index, smt.depth)
+	old = copyInt(smt.leafOrZero(key))
+	if err := smt.set(key, value); err != nil {
+		return nil, nil, err
+	}
+	return old, copyInt(smt.root.Data), nil
+}
+
+// InsertWithProof sets the leaf at index to value and returns a transition
+// proof from the old root to the new one, for circuits and light clients
+// that verify state transitions with VerifyTransitionProof.
+func (smt *SparseMerkleTree) InsertWithProof(index int, value *big.Int) (*TransitionProof, error) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+
+	key := getPaddedBinaryString(index, smt.depth)
+	proof := &TransitionProof{
+		Index:   index,
+		OldLeaf: smt.leafOrZero(key),
+		NewLeaf: value,
+		OldRoot: smt.root.Data,
+		Path:    smt.generateMerklePath(smt.root, key),
+	}
+	if err := smt.set(key, value); err != nil {
+		return nil, err
+	}
+	proof.NewRoot = smt.root.Data
+	return proof, nil
+}
+
+// Get returns the value of the leaf at index, if one was inserted.
+func (smt *SparseMerkleTree) Get(index int) (*big.Int, bool) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if checkIndex(index, smt.depth) != nil {
+		return nil, false
+	}
+	value, exists := smt.leaves[getPaddedBinaryString(index, smt.depth)]
+	return copyInt(value), exists
+}
+
+// Len returns the number of leaves holding a value other than the zero leaf.
+func (smt *SparseMerkleTree) Len() int {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return smt.nonDefaultLeaves
+}
+
+// IsSet reports whether a leaf was inserted at index, even if it was set to
+// the zero leaf. Unlike Has, it distinguishes a leaf explicitly set to the
+// zero leaf from one never set.
+func (smt *SparseMerkleTree) IsSet(index int) bool {
+	_, exists := smt.Get(index)
+	return exists
+}
+
+// Has reports whether the leaf at index holds a value other than the zero
+// leaf.
+func (smt *SparseMerkleTree) Has(index int) bool {
+	value, exists := smt.Get(index)
+	return exists && value.Cmp(smt.zeroLeaf) != 0
+}
+
+// insert inserts a leaf with the given binary key and value into the tree,
+// dropping the metadata recorded for the previous leaf, see setLeaf. The
+// caller must hold the write lock.
+func (smt *SparseMerkleTree) insert(key string, value *big.Int) {
+	smt.setLeaf(key, value)
+	smt.root = smt.insertIntoNode(smt.root, key, value, 0)
+}
+
+// set sets the leaf with the given binary key to value as a single commit,
+// after checking that the key addresses a leaf of the tree, that value is a
+// valid leaf other than the tombstone and that setting it does not exceed
+// the capacity of the tree. Every single-leaf mutation goes through set. The
+// caller must hold the write lock.
+func (smt *SparseMerkleTree) set(key string, value *big.Int) error {
+	if err := smt.put(key, value); err != nil {
+		return err
+	}
+	smt.commit()
+	return nil
+}
+
+// put implements set without committing, so that several validated writes
+// can share one commit. The caller must hold the write lock.
+func (smt *SparseMerkleTree) put(key string, value *big.Int) error {
+	if err := smt.checkNotTombstone(value); err != nil {
+		return err
+	}
+	return smt.store(key, value)
+}
+
+// store implements put without rejecting the tombstone, which only entomb
+// writes. The caller must hold the write lock.
+func (smt *SparseMerkleTree) store(key string, value *big.Int) error {
+	if err := checkKey(key, smt.depth); err != nil {
+		return err
 	}
+	if err := checkValue(value); err != nil {
+		return err
+	}
+	if err := smt.checkGrowth(smt.growth(key, value)); err != nil {
+		return err
+	}
+	smt.insert(key, copyInt(value))
+	return nil
+}
+
+// commit records a new version of the tree after a mutating operation. The
+// caller must hold the write lock.
+func (smt *SparseMerkleTree) commit() {
+	smt.version++
+	smt.committedAt = time.Now()
+
+	smt.recordStats()
+
+	head := smt.head()
+	smt.recentHeads = append(smt.recentHeads, head)
+	if len(smt.recentHeads) > maxRecentHeads {
+		smt.recentHeads = smt.recentHeads[len(smt.recentHeads)-maxRecentHeads:]
+	}
+	smt.publish(head)
+	smt.notifyWatchers(head)
+	smt.warnOccupancy(head)
+}
+
+// Head returns the tree head of the current version of the tree.
+func (smt *SparseMerkleTree) Head() TreeHead {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return smt.head()
+}
+
+// head returns the tree head of the current version of the tree. The caller
+// must hold the lock.
+func (smt *SparseMerkleTree) head() TreeHead {
+	return TreeHead{
+		Depth:     smt.depth,
+		ZeroLeaf:  copyInt(smt.zeroLeaf),
+		Root:      copyInt(smt.root.Data),
+		Version:   smt.version,
+		Timestamp: smt.committedAt,
+		Hasher:    smt.hasher.Fingerprint(),
+	}
+}
+
+// insertIntoNode returns a copy of node, at the specified depth, with the
+// leaf inserted, counting its hashes for the next commit. Only the nodes on
+// the path to the leaf are copied.
+func (smt *SparseMerkleTree) insertIntoNode(node *MerkleNode, key string, value *big.Int, depth int) *MerkleNode {
+	return smt.insertWith(node, key, value, depth, smt.hashNode)
+}
 
-	if depth == maxDepth {
-		return &MerkleNode{Data: value}
+// insertWith implements insertIntoNode, hashing nodes with hash.
+func (smt *SparseMerkleTree) insertWith(node *MerkleNode, key string, value *big.Int, depth int, hash hashFunc) *MerkleNode {
+	if depth == smt.depth {
+		return smt.leafNode(key, value)
+	}
+	if smt.compressed && (node == nil || node.leafKey == key) {
+		return smt.compressedNode(key, value, depth, hash)
 	}
 
 	pathBit := getPathBit(key, depth)
+	if node.isCompressed() {
+		node = smt.expand(node, depth, getPathBit(node.leafKey, depth) == pathBit, hash)
+	} else {
+		node = copyNode(node)
+	}
 	if pathBit == 0 {
-		node.Left = smt.insertIntoNode(node.getLeftChild(depth+1, smt.ZeroLeaf), key, value, depth+1, maxDepth)
+		node.Left = smt.insertWith(node.Left, key, value, depth+1, hash)
 	} else {
-		node.Right = smt.insertIntoNode(node.getRightChild(depth+1, smt.ZeroLeaf), key, value, depth+1, maxDepth)
+		node.Right = smt.insertWith(node.Right, key, value, depth+1, hash)
 	}
 
-	node.Data = hashChildren(node.Left, node.Right, maxDepth-depth, smt.ZeroLeaf)
+	node.Data = hash(node.Left, node.Right, smt.depth-depth)
 	return node
 }
 
-// getLeftChild returns the left child node of the current node.
-func (node *MerkleNode) getLeftChild(depth int, zeroLeaf *big.Int) *MerkleNode {
-	if node.Left == nil {
-		return &MerkleNode{Data: getHashEmptyForDepth(depth, zeroLeaf), Left: nil, Right: nil}
+// copyNode returns a copy of node sharing its children, or a new node if node
+// is nil. Nodes are never modified once they are part of a committed version,
+// so versions kept by TagHeight, Clone and KeyHistory share every subtree a
+// later commit did not change.
+func copyNode(node *MerkleNode) *MerkleNode {
+	if node == nil {
+		return &MerkleNode{}
 	}
-	return node.Left
+	return &MerkleNode{Left: node.Left, Right: node.Right, Data: node.Data, leafKey: node.leafKey, leafValue: node.leafValue}
 }
 
-// getRightChild returns the right child node of the current node.
-func (node *MerkleNode) getRightChild(depth int, zeroLeaf *big.Int) *MerkleNode {
-	if node.Right == nil {
-		return &MerkleNode{Data: getHashEmptyForDepth(depth, zeroLeaf), Left: nil, Right: nil}
-	}
-	return node.Right
+// hashFunc hashes a node of the given height from its children.
+type hashFunc func(left, right *MerkleNode, height int) *big.Int
+
+// hashNode computes the hash of a node of the given height from its children,
+// using the precomputed empty subtree hash for missing children, and counts
+// it for the statistics of the next commit.
+func (smt *SparseMerkleTree) hashNode(left, right *MerkleNode, height int) *big.Int {
+	smt.hashes.Add(1)
+	return smt.nodeHash(left, right, height)
 }
 
-// GenerateMerklePath generates a Merkle tree path for the leaf with the given index.
-func (smt *SparseMerkleTree) GenerateMerklePath(index int) ([]*MerklePathItem, error) {
-	key := getPaddedBinaryString(int(index), smt.Depth)
-	if _, exists := smt.Leaves[key]; !exists {
-		return nil, fmt.Errorf("no leaf exists at key: %s", key)
+// nodeHash returns the hash of a node with the given children at the given
+// height without counting it for the next commit, for hashing that does not
+// change the tree.
+func (smt *SparseMerkleTree) nodeHash(left, right *MerkleNode, height int) *big.Int {
+	leftData, rightData := smt.emptyHashes[height-1], smt.emptyHashes[height-1]
+	if left != nil {
+		leftData = left.Data
+	}
+	if right != nil {
+		rightData = right.Data
 	}
 
-	path := make([]*MerklePathItem, smt.Depth)
-	current := smt.Root
-	for depth := 0; depth < smt.Depth; depth++ {
-		pathBit := getPathBit(key, depth)
-		if pathBit == 0 {
-			path[depth] = &MerklePathItem{
-				SiblingHash: current.getRightChild(depth+1, smt.ZeroLeaf).Data,
-				IsRight:     true,
+	hash, _ := smt.hasher.Hash(leftData, rightData)
+	return hash
+}
+
+// nodeAt returns the node at the given binary path prefix, or nil if the
+// subtree at that position is empty.
+func (smt *SparseMerkleTree) nodeAt(prefix string) *MerkleNode {
+	return smt.nodeIn(smt.root, prefix)
+}
+
+// nodeIn returns the node at the given binary path prefix of the tree rooted
+// at root, or nil if the subtree at that position is empty. Below a
+// compressed node, it returns a new compressed node for the position.
+func (smt *SparseMerkleTree) nodeIn(root *MerkleNode, prefix string) *MerkleNode {
+	current := root
+	for depth := 0; depth < len(prefix) && current != nil; depth++ {
+		if current.isCompressed() {
+			if !strings.HasPrefix(current.leafKey, prefix) {
+				return nil
 			}
-			current = current.getLeftChild(depth+1, smt.ZeroLeaf)
+			return smt.compressedNode(current.leafKey, current.leafValue, len(prefix), smt.nodeHash)
+		}
+		if getPathBit(prefix, depth) == 0 {
+			current = current.Left
 		} else {
-			path[depth] = &MerklePathItem{
-				SiblingHash: current.getLeftChild(depth+1, smt.ZeroLeaf).Data,
-				IsRight:     false,
-			}
-			current = current.getRightChild(depth+1, smt.ZeroLeaf)
+			current = current.Right
 		}
 	}
+	return current
+}
 
-	// Reverse path
-	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
-		path[i], path[j] = path[j], path[i]
+// prefixHash returns the hash of the node at the given binary path prefix,
+// the hash of an empty subtree if none of its leaves is set. The caller must
+// hold the lock.
+func (smt *SparseMerkleTree) prefixHash(prefix string) *big.Int {
+	if node := smt.nodeAt(prefix); node != nil {
+		return node.Data
 	}
+	return smt.emptyHashes[smt.depth-len(prefix)]
+}
+
+// GenerateMerklePath generates a Merkle tree path for the leaf with the given
+// index. It returns an error if no leaf was inserted at index; use
+// GenerateMerklePathAny to prove the zero leaf of an unset index.
+func (smt *SparseMerkleTree) GenerateMerklePath(index int) ([]*MerklePathItem, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
 
-	return path, nil
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	if _, exists := smt.leaves[key]; !exists {
+		return nil, fmt.Errorf("no leaf exists at key: %s", key)
+	}
+
+	return smt.generateMerklePath(smt.root, key), nil
 }
 
-// VerifyMerklePath verifies a Merkle tree path against the expected root hash.
-func VerifyMerklePath(leafHash *big.Int, path []*MerklePathItem, expectedRoot *big.Int) bool {
-	currentHash := leafHash
-	for _, item := range path {
-		siblingHash := item.SiblingHash
+// GenerateMerklePathAny generates a Merkle tree path for the leaf at index,
+// whether or not a leaf was inserted there. The path verifies against the
+// current value of the leaf, as returned by Leaf.
+func (smt *SparseMerkleTree) GenerateMerklePathAny(index int) ([]*MerklePathItem, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
 
-		if item.IsRight {
-			currentHash, _ = poseidon.Hash([]*big.Int{currentHash, siblingHash})
-		} else {
-			currentHash, _ = poseidon.Hash([]*big.Int{siblingHash, currentHash})
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	return smt.generateMerklePath(smt.root, getPaddedBinaryString(index, smt.depth)), nil
+}
+
+// GenerateExclusionPath generates a Merkle tree path proving that no leaf was
+// inserted at index. The path verifies against the zero leaf of the tree,
+// whatever its value.
+func (smt *SparseMerkleTree) GenerateExclusionPath(index int) ([]*MerklePathItem, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	if _, exists := smt.leaves[key]; exists {
+		return nil, fmt.Errorf("leaf exists at key: %s", key)
+	}
+	return smt.generateMerklePath(smt.root, key), nil
+}
+
+// generateMerklePath generates a Merkle tree path for the given key in the
+// tree rooted at root, whether or not a leaf was inserted there. Once the
+// walk leaves the stored nodes, every remaining sibling is an empty subtree,
+// so deep trees cost no allocations beyond the path itself.
+func (smt *SparseMerkleTree) generateMerklePath(root *MerkleNode, key string) []*MerklePathItem {
+	path := make([]*MerklePathItem, smt.depth)
+	items := make([]MerklePathItem, smt.depth)
+	current := root
+	for depth := 0; depth < smt.depth; depth++ {
+		height := smt.depth - depth - 1
+		item := &items[height]
+		item.SiblingHash = copyInt(smt.emptyHashes[height])
+		item.IsRight = getPathBit(key, depth) == 0
+		if current.isCompressed() {
+			// The leaf of a compressed node is the only one below it: the
+			// siblings are empty up to the level where the paths diverge.
+			if getPathBit(current.leafKey, depth) != getPathBit(key, depth) {
+				item.SiblingHash = copyInt(smt.compressedNode(current.leafKey, current.leafValue, depth+1, smt.nodeHash).Data)
+				current = nil
+			}
+		} else if current != nil {
+			sibling, next := current.Right, current.Left
+			if !item.IsRight {
+				sibling, next = current.Left, current.Right
+			}
+			if sibling != nil {
+				item.SiblingHash = copyInt(sibling.Data)
+			}
+			current = next
 		}
+		path[height] = item
 	}
 
-	return currentHash.Cmp(expectedRoot) == 0
+	return path
+}
+
+// PathIndices returns the path bits of a leaf-to-root path, ordered like its
+// siblings, as the pathIndices signal of circom Merkle inclusion circuits: 1
+// where the path node is a right child and 0 otherwise.
+func PathIndices(path []*MerklePathItem) []int {
+	return verify.PathIndices(path)
+}
+
+// VerifyMerklePath verifies a Merkle tree path against the expected root hash.
+func VerifyMerklePath(leafHash *big.Int, path []*MerklePathItem, expectedRoot *big.Int) bool {
+	return verify.VerifyMerklePath(leafHash, path, expectedRoot)
 }
@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/iden3/go-iden3-crypto/constants"
 	"github.com/iden3/go-iden3-crypto/poseidon"
 	"github.com/stretchr/testify/assert"
 )
@@ -13,9 +14,9 @@ var zeroLeaf, _ = poseidon.Hash([]*big.Int{big.NewInt(0)})
 func TestNewSparseMerkleTree(t *testing.T) {
 	smt := NewSparseMerkleTree(2, zeroLeaf)
 	assert.NotNil(t, smt)
-	assert.NotNil(t, smt.Root)
-	assert.Equal(t, 2, smt.Depth)
-	assert.Empty(t, smt.Leaves)
+	assert.NotNil(t, smt.root)
+	assert.Equal(t, 2, smt.depth)
+	assert.Empty(t, smt.leaves)
 
 	tests := []struct {
 		index        int
@@ -46,16 +47,16 @@ func TestNewSparseMerkleTree(t *testing.T) {
 
 	initRoot := new(big.Int)
 	initRoot.SetString("2186774891605521484511138647132707263205739024356090574223746683689524510919", 10)
-	if smt.Root.Data.Cmp(initRoot) != 0 {
-		t.Error("Expected root node data to be", initRoot, "got", smt.Root.Data)
+	if smt.root.Data.Cmp(initRoot) != 0 {
+		t.Error("Expected root node data to be", initRoot, "got", smt.root.Data)
 	}
 
 	for _, test := range tests {
 		smt.Insert(test.index, test.value)
 		expectedRoot := new(big.Int)
 		expectedRoot.SetString(test.expectedRoot, 10)
-		if smt.Root.Data.Cmp(expectedRoot) != 0 {
-			t.Error("Expected root node data to be", expectedRoot, "got", smt.Root.Data)
+		if smt.root.Data.Cmp(expectedRoot) != 0 {
+			t.Error("Expected root node data to be", expectedRoot, "got", smt.root.Data)
 		}
 	}
 }
@@ -66,9 +67,167 @@ func TestInsert(t *testing.T) {
 	index := 0
 	value := big.NewInt(5)
 
-	smt.Insert(index, value)
+	assert.NoError(t, smt.Insert(index, value))
 
-	assert.Equal(t, value, smt.Leaves[getPaddedBinaryString(index, smt.Depth)])
+	assert.Equal(t, value, smt.leaves[getPaddedBinaryString(index, smt.depth)])
+}
+
+func TestInsertOutOfRange(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	root := smt.root.Data
+
+	assert.ErrorIs(t, smt.Insert(8, big.NewInt(5)), ErrIndexOutOfRange)
+	assert.ErrorIs(t, smt.Insert(-1, big.NewInt(5)), ErrIndexOutOfRange)
+	assert.Error(t, smt.Insert(0, nil))
+
+	assert.Equal(t, root, smt.root.Data)
+	assert.Empty(t, smt.leaves)
+}
+
+func TestInsertInvalidValue(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	root := smt.root.Data
+
+	assert.ErrorIs(t, smt.Insert(0, nil), ErrInvalidValue)
+	assert.ErrorIs(t, smt.Insert(0, big.NewInt(-1)), ErrInvalidValue)
+	assert.ErrorIs(t, smt.Insert(0, new(big.Int).Set(constants.Q)), ErrInvalidValue)
+	assert.ErrorIs(t, smt.InsertIfAbsent(0, new(big.Int).Add(constants.Q, big.NewInt(1))), ErrInvalidValue)
+	_, err := smt.InsertHashed(KeyDomainBytes, []byte("id"), big.NewInt(-5))
+	assert.ErrorIs(t, err, ErrInvalidValue)
+
+	assert.Equal(t, root, smt.root.Data)
+	assert.Empty(t, smt.leaves)
+
+	largest := new(big.Int).Sub(constants.Q, big.NewInt(1))
+	assert.NoError(t, smt.Insert(0, largest))
+}
+
+func TestUpdate(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+
+	old, root, err := smt.Update(2, big.NewInt(5))
+	assert.NoError(t, err)
+	assert.Equal(t, zeroLeaf, old)
+	assert.Equal(t, smt.root.Data, root)
+
+	old, _, err = smt.Update(2, big.NewInt(6))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), old)
+
+	_, _, err = smt.Update(8, big.NewInt(1))
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestInsertWithProof(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, smt.Insert(1, big.NewInt(10)))
+	oldRoot := smt.Root()
+
+	proof, err := smt.InsertWithProof(2, big.NewInt(5))
+	assert.NoError(t, err)
+	assert.Equal(t, zeroLeaf, proof.OldLeaf)
+	assert.Equal(t, oldRoot, proof.OldRoot)
+	assert.Equal(t, smt.Root(), proof.NewRoot)
+	assert.True(t, VerifyTransitionProof(proof))
+
+	proof, err = smt.InsertWithProof(2, big.NewInt(6))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), proof.OldLeaf)
+	assert.True(t, VerifyTransitionProof(proof))
+	proof.NewLeaf = big.NewInt(7)
+	assert.False(t, VerifyTransitionProof(proof))
+
+	_, err = smt.InsertWithProof(8, big.NewInt(1))
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestAccessors(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.Equal(t, 3, smt.Depth())
+	assert.Equal(t, zeroLeaf, smt.ZeroLeaf())
+
+	assert.NoError(t, smt.Insert(4, big.NewInt(5)))
+	assert.Equal(t, smt.root.Data, smt.Root())
+
+	leaf, err := smt.Leaf(4)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), leaf)
+	leaf, err = smt.Leaf(5)
+	assert.NoError(t, err)
+	assert.Equal(t, zeroLeaf, leaf)
+	_, err = smt.Leaf(8)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestAccessorsReturnCopies(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	value := big.NewInt(5)
+	assert.NoError(t, smt.Insert(4, value))
+	root := new(big.Int).Set(smt.Root())
+
+	value.SetInt64(6)
+	smt.Root().SetInt64(1)
+	smt.Head().Root.SetInt64(1)
+	smt.ZeroLeaf().SetInt64(1)
+	got, _ := smt.Get(4)
+	got.SetInt64(99)
+	leaf, _ := smt.Leaf(4)
+	leaf.SetInt64(99)
+	path, _ := smt.GenerateMerklePath(4)
+	path[0].SiblingHash.SetInt64(1)
+
+	assert.Equal(t, root, smt.Root())
+	assert.Equal(t, zeroLeaf, smt.ZeroLeaf())
+	got, _ = smt.Get(4)
+	assert.Equal(t, big.NewInt(5), got)
+	path, _ = smt.GenerateMerklePath(4)
+	assert.True(t, VerifyMerklePath(got, path, smt.Root()))
+	fresh := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, fresh.Insert(4, big.NewInt(5)))
+	assert.Equal(t, fresh.Root(), smt.Root(), "the stored leaf and the root stay in sync")
+}
+
+func TestGet(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(6, big.NewInt(5))
+
+	value, ok := smt.Get(6)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(5), value)
+
+	_, ok = smt.Get(5)
+	assert.False(t, ok)
+	_, ok = smt.Get(8)
+	assert.False(t, ok)
+}
+
+func TestLen(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	assert.NoError(t, smt.Insert(1, big.NewInt(5)))
+	assert.NoError(t, smt.Insert(2, zeroLeaf))
+	assert.NoError(t, smt.Insert(3, big.NewInt(6)))
+	assert.Equal(t, 2, smt.Len())
+
+	assert.True(t, smt.IsSet(2), "explicitly set to the zero leaf")
+	assert.False(t, smt.IsSet(4), "never set")
+
+	assert.NoError(t, smt.Insert(1, zeroLeaf))
+	assert.NoError(t, smt.Insert(2, big.NewInt(7)))
+	assert.NoError(t, smt.Delete(3))
+	_, err := smt.BatchInsert([]LeafUpdate{{Index: 4, Value: big.NewInt(1)}, {Index: 5, Value: zeroLeaf}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, smt.Len())
+}
+
+func TestHas(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	smt.Insert(1, big.NewInt(5))
+	smt.Insert(2, zeroLeaf)
+
+	assert.True(t, smt.Has(1))
+	assert.False(t, smt.Has(2), "a leaf set to the zero leaf is empty")
+	assert.False(t, smt.Has(3))
+	assert.False(t, smt.Has(-1))
 }
 
 func TestGetPaddedBinaryString(t *testing.T) {
@@ -81,10 +240,10 @@ func TestGetPaddedBinaryString(t *testing.T) {
 func TestNewDeterministicSparseMerkleTree(t *testing.T) {
 	smt := NewDeterministicSparseMerkleTree(3, zeroLeaf)
 	assert.NotNil(t, smt)
-	assert.NotNil(t, smt.Root)
-	assert.Equal(t, 3, smt.Depth)
-	assert.NotEmpty(t, smt.Leaves)
-	assert.Len(t, smt.Leaves, 8)
+	assert.NotNil(t, smt.root)
+	assert.Equal(t, 3, smt.depth)
+	assert.NotEmpty(t, smt.leaves)
+	assert.Len(t, smt.leaves, 8)
 }
 
 // This test will depend on the poseidon.Hash function behavior.
@@ -92,8 +251,8 @@ func TestMerkleNodeHashes(t *testing.T) {
 	smt := NewDeterministicSparseMerkleTree(3, zeroLeaf)
 
 	// Test the root hash
-	expectedRootHash := smt.Root.Data
-	actualRootHash := hashChildren(smt.Root.Left, smt.Root.Right, smt.Depth, zeroLeaf)
+	expectedRootHash := smt.root.Data
+	actualRootHash := hashChildren(smt.root.Left, smt.root.Right, smt.depth, zeroLeaf)
 
 	assert.Equal(t, expectedRootHash, actualRootHash)
 }
@@ -131,9 +290,66 @@ func TestSparseMerkleTree(t *testing.T) {
 
 	for i := 0; i < (1 << depth); i++ {
 		key := getPaddedBinaryString(i, depth)
-		value := smt.Leaves[key]
+		value := smt.leaves[key]
 		path, _ := smt.GenerateMerklePath(i)
-		valid := VerifyMerklePath(value, path, smt.Root.Data)
+		valid := VerifyMerklePath(value, path, smt.root.Data)
 		assert.True(t, valid, "The Merkle path should be valid for all leaves")
 	}
 }
+
+func TestGenerateMerklePathSparse(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	smt.Insert(3, big.NewInt(3))
+	smt.Insert(12, big.NewInt(12))
+
+	for _, index := range []int{3, 12} {
+		path, err := smt.GenerateMerklePath(index)
+		assert.NoError(t, err)
+		assert.True(t, VerifyMerklePath(big.NewInt(int64(index)), path, smt.root.Data), "empty siblings must hash as empty subtrees of their height")
+	}
+}
+
+func TestGenerateMerklePathAny(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(3, big.NewInt(3)))
+
+	for _, index := range []int{3, 4} {
+		leaf, err := smt.Leaf(index)
+		assert.NoError(t, err)
+		path, err := smt.GenerateMerklePathAny(index)
+		assert.NoError(t, err)
+		assert.True(t, VerifyMerklePath(leaf, path, smt.Root()))
+	}
+	_, err := smt.GenerateMerklePathAny(16)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestSentinelZeroLeaf(t *testing.T) {
+	sentinel := big.NewInt(0xdead)
+	smt := NewSparseMerkleTree(4, sentinel)
+	assert.Equal(t, getEmptyHashes(4, sentinel)[4], smt.Root())
+	assert.NotEqual(t, NewSparseMerkleTree(4, zeroLeaf).Root(), smt.Root())
+
+	assert.NoError(t, smt.Insert(6, big.NewInt(1)))
+	leaf, err := smt.Leaf(7)
+	assert.NoError(t, err)
+	assert.Equal(t, sentinel, leaf)
+
+	path, err := smt.GenerateExclusionPath(7)
+	assert.NoError(t, err)
+	assert.True(t, VerifyMerklePath(sentinel, path, smt.Root()))
+	assert.False(t, VerifyMerklePath(zeroLeaf, path, smt.Root()))
+
+	_, err = smt.GenerateExclusionPath(6)
+	assert.Error(t, err)
+	_, err = smt.GenerateExclusionPath(16)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestPathIndices(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(6, big.NewInt(60)))
+	path, err := smt.GenerateMerklePath(6)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 1, 0}, PathIndices(path), "the bits of 6, least significant first")
+}
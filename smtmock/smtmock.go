@@ -0,0 +1,221 @@
+/*
+Package smtmock provides in-memory fakes of the interfaces of package smt, so
+services can be unit-tested without building Poseidon state.
+
+The fakes keep leaves in a map and never hash. Their roots are version
+counters and their claims carry no Merkle paths, so they only pass a fake
+Verifier.
+*/
+package smtmock
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/pycckuu/smt"
+)
+
+// Tree is a fake smt.Tree and smt.Prover holding leaves in a map. Its root is
+// the number of commits, not a hash.
+type Tree struct {
+	Depth    int      // Depth of the tree, bounding valid indices.
+	ZeroLeaf *big.Int // Value of unset leaves.
+	Err      error    // Error returned by every method that can fail, if set.
+
+	mu      sync.Mutex
+	leaves  map[int]*big.Int
+	version int
+}
+
+// NewTree creates an empty fake tree of the given depth.
+func NewTree(depth int, zeroLeaf *big.Int) *Tree {
+	return &Tree{Depth: depth, ZeroLeaf: zeroLeaf}
+}
+
+// Insert implements smt.Tree.
+func (t *Tree) Insert(index int, value *big.Int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.check(index); err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("%w: nil value", smt.ErrInvalidValue)
+	}
+	if t.leaves == nil {
+		t.leaves = make(map[int]*big.Int)
+	}
+	t.leaves[index] = value
+	t.version++
+	return nil
+}
+
+// Delete implements smt.Tree.
+func (t *Tree) Delete(index int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.check(index); err != nil {
+		return err
+	}
+	if _, exists := t.leaves[index]; exists {
+		delete(t.leaves, index)
+		t.version++
+	}
+	return nil
+}
+
+// Leaf implements smt.Tree.
+func (t *Tree) Leaf(index int) (*big.Int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.check(index); err != nil {
+		return nil, err
+	}
+	if value, exists := t.leaves[index]; exists {
+		return value, nil
+	}
+	return t.ZeroLeaf, nil
+}
+
+// Has implements smt.Tree.
+func (t *Tree) Has(index int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, exists := t.leaves[index]
+	return exists
+}
+
+// Len implements smt.Tree.
+func (t *Tree) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, value := range t.leaves {
+		if t.ZeroLeaf == nil || value.Cmp(t.ZeroLeaf) != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Root implements smt.Tree.
+func (t *Tree) Root() *big.Int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return big.NewInt(int64(t.version))
+}
+
+// Head implements smt.Tree.
+func (t *Tree) Head() smt.TreeHead {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.head()
+}
+
+// MembershipClaim implements smt.Prover.
+func (t *Tree) MembershipClaim(index int) (*smt.MembershipClaim, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.check(index); err != nil {
+		return nil, err
+	}
+	value, exists := t.leaves[index]
+	if !exists {
+		return nil, fmt.Errorf("no leaf exists at index: %d", index)
+	}
+	return &smt.MembershipClaim{Index: index, Leaf: value}, nil
+}
+
+// NonMembershipClaim implements smt.Prover.
+func (t *Tree) NonMembershipClaim(index int) (*smt.NonMembershipClaim, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.check(index); err != nil {
+		return nil, err
+	}
+	if _, exists := t.leaves[index]; exists {
+		return nil, fmt.Errorf("leaf exists at index: %d", index)
+	}
+	return &smt.NonMembershipClaim{Index: index}, nil
+}
+
+// NewBundle implements smt.Prover.
+func (t *Tree) NewBundle(members, nonMembers []int) (*smt.Bundle, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bundle := &smt.Bundle{Head: t.head()}
+	for _, index := range members {
+		if err := t.check(index); err != nil {
+			return nil, err
+		}
+		value, exists := t.leaves[index]
+		if !exists {
+			return nil, fmt.Errorf("no leaf exists at index: %d", index)
+		}
+		bundle.Inclusions = append(bundle.Inclusions, smt.BundleProof{Index: index, Leaf: value})
+	}
+	for _, index := range nonMembers {
+		if err := t.check(index); err != nil {
+			return nil, err
+		}
+		if _, exists := t.leaves[index]; exists {
+			return nil, fmt.Errorf("leaf exists at index: %d", index)
+		}
+		bundle.Exclusions = append(bundle.Exclusions, smt.BundleProof{Index: index})
+	}
+	return bundle, nil
+}
+
+// check returns the injected error, or smt.ErrIndexOutOfRange if index does
+// not address a leaf. The caller must hold the lock.
+func (t *Tree) check(index int) error {
+	if t.Err != nil {
+		return t.Err
+	}
+	if index < 0 || (t.Depth < 63 && index >= 1<<t.Depth) {
+		return fmt.Errorf("%w: %d", smt.ErrIndexOutOfRange, index)
+	}
+	return nil
+}
+
+// head returns the fake head of the tree. The caller must hold the lock.
+func (t *Tree) head() smt.TreeHead {
+	return smt.TreeHead{Depth: t.Depth, ZeroLeaf: t.ZeroLeaf, Root: big.NewInt(int64(t.version)), Version: t.version}
+}
+
+// Verifier is a fake smt.Verifier that records the claims it is asked to
+// verify and accepts them all unless Err is set.
+type Verifier struct {
+	Err error // Error returned by Verify, if set.
+
+	mu     sync.Mutex
+	claims []smt.Claim
+}
+
+// Verify implements smt.Verifier.
+func (v *Verifier) Verify(claim smt.Claim, trustedRoot, zeroLeaf *big.Int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.claims = append(v.claims, claim)
+	return v.Err
+}
+
+// Claims returns the claims passed to Verify, in order.
+func (v *Verifier) Claims() []smt.Claim {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return append([]smt.Claim(nil), v.claims...)
+}
@@ -0,0 +1,53 @@
+package smtmock
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/pycckuu/smt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	var tree smt.Tree = NewTree(4, big.NewInt(0))
+	var prover smt.Prover = tree.(*Tree)
+
+	assert.NoError(t, tree.Insert(3, big.NewInt(30)))
+	assert.ErrorIs(t, tree.Insert(16, big.NewInt(1)), smt.ErrIndexOutOfRange)
+	assert.True(t, tree.Has(3))
+	assert.Equal(t, 1, tree.Len())
+	assert.Equal(t, 1, tree.Head().Version)
+	leaf, err := tree.Leaf(4)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), leaf)
+
+	claim, err := prover.MembershipClaim(3)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(30), claim.Leaf)
+	_, err = prover.NonMembershipClaim(3)
+	assert.Error(t, err)
+	bundle, err := prover.NewBundle([]int{3}, []int{4})
+	assert.NoError(t, err)
+	assert.Len(t, bundle.Inclusions, 1)
+	assert.Len(t, bundle.Exclusions, 1)
+
+	assert.NoError(t, tree.Delete(3))
+	assert.False(t, tree.Has(3))
+	assert.Equal(t, big.NewInt(2), tree.Root())
+
+	failure := errors.New("unavailable")
+	tree.(*Tree).Err = failure
+	assert.ErrorIs(t, tree.Insert(1, big.NewInt(1)), failure)
+}
+
+func TestVerifier(t *testing.T) {
+	var verifier Verifier
+	claim := &smt.MembershipClaim{Index: 3, Leaf: big.NewInt(30)}
+	assert.NoError(t, verifier.Verify(claim, big.NewInt(1), nil))
+	assert.Equal(t, []smt.Claim{claim}, verifier.Claims())
+
+	verifier.Err = errors.New("invalid")
+	assert.Error(t, verifier.Verify(claim, big.NewInt(1), nil))
+	assert.Error(t, smt.ClaimVerifier.Verify(claim, big.NewInt(1), nil), "fake claims carry no paths")
+}
@@ -0,0 +1,274 @@
+package smt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// maxSnapshotDepth bounds the depth accepted from a snapshot header, so a
+// corrupt header cannot trigger huge allocations.
+const maxSnapshotDepth = 256
+
+// ErrInvalidSnapshot is returned when a snapshot is malformed or its leaves do
+// not hash to the root in its header.
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// SnapshotHeader is the first record of a snapshot.
+type SnapshotHeader struct {
+	Head      TreeHead `json:"head"`      // Head of the tree at the time of the snapshot.
+	LeafCount int      `json:"leafCount"` // Number of leaf records that follow.
+}
+
+// SnapshotLeaf is a leaf record of a snapshot. The index is a big integer so
+// that snapshots of trees deeper than 62 levels, such as address-keyed trees,
+// can be written.
+type SnapshotLeaf struct {
+	Index *big.Int `json:"index"`         // Index of the leaf.
+	Value *big.Int `json:"value"`         // Value of the leaf.
+	Key   string   `json:"key,omitempty"` // Application key of the leaf, if known.
+}
+
+// WriteSnapshot writes the current state of the tree to w as a stream of JSON
+// records: a SnapshotHeader followed by one SnapshotLeaf per inserted leaf in
+// ascending index order.
+func (smt *SparseMerkleTree) WriteSnapshot(w io.Writer) error {
+	_, err := smt.WriteSnapshotContext(context.Background(), w, nil)
+	return err
+}
+
+// SnapshotDigest returns the SHA-256 digest of the state of the tree: a
+// snapshot of it with the version and commit time left out. Two operators
+// holding the same leaves and key names get the same digest however they
+// built their trees, so they can compare full state with a single hash.
+func (smt *SparseMerkleTree) SnapshotDigest() ([32]byte, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	leaves := smt.snapshotLeaves()
+	head := TreeHead{Depth: smt.depth, ZeroLeaf: smt.zeroLeaf, Root: smt.root.Data, Hasher: smt.hasher.Fingerprint()}
+
+	digest := sha256.New()
+	encoder := json.NewEncoder(digest)
+	if err := encoder.Encode(SnapshotHeader{Head: head, LeafCount: len(leaves)}); err != nil {
+		return [32]byte{}, err
+	}
+	for _, leaf := range leaves {
+		if err := encoder.Encode(leaf); err != nil {
+			return [32]byte{}, err
+		}
+	}
+
+	var sum [32]byte
+	copy(sum[:], digest.Sum(nil))
+	return sum, nil
+}
+
+// snapshotLeaves returns the leaf records of the tree in ascending index
+// order. The caller must hold the lock.
+func (smt *SparseMerkleTree) snapshotLeaves() []SnapshotLeaf {
+	keys := make([]string, 0, len(smt.leaves))
+	for key := range smt.leaves {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	leaves := make([]SnapshotLeaf, len(keys))
+	for i, key := range keys {
+		leaves[i] = SnapshotLeaf{Index: getBigIndexFromBinaryString(key), Value: smt.leaves[key], Key: smt.keyName(key)}
+	}
+	return leaves
+}
+
+// ReadSnapshot rebuilds a tree from a snapshot written by WriteSnapshot,
+// verifying it as ValidateSnapshot does.
+func ReadSnapshot(r io.Reader) (*SparseMerkleTree, error) {
+	var smt *SparseMerkleTree
+	_, err := readSnapshot(r, func(header SnapshotHeader, hasher Hasher) error {
+		var err error
+		smt, err = newTreeWithHasher(header.Head.Depth, header.Head.ZeroLeaf, hasher)
+		return err
+	}, func(key string, leaf SnapshotLeaf) {
+		smt.insert(key, leaf.Value)
+		if leaf.Key != "" {
+			smt.setKeyName(key, leaf.Key)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	smt.commit()
+	return smt, nil
+}
+
+// ValidateSnapshot checks that a snapshot is well formed, that it was hashed
+// with PoseidonHasher or a hasher passed to RegisterHasher, that its leaf
+// count matches its header and that its leaves hash to the root in its
+// header. It streams the leaves and keeps only one pending hash per level in
+// memory, so backups and third-party dumps can be vetted without building the
+// tree.
+func ValidateSnapshot(r io.Reader) (SnapshotHeader, error) {
+	return readSnapshot(r, nil, nil)
+}
+
+// readSnapshot parses and verifies a snapshot, calling onHeader and onLeaf, if
+// set, for each record as it is read. onHeader is passed the hasher named by
+// the header, and onLeaf the binary key of the leaf with the record.
+func readSnapshot(r io.Reader, onHeader func(SnapshotHeader, Hasher) error, onLeaf func(key string, leaf SnapshotLeaf)) (SnapshotHeader, error) {
+	decoder := json.NewDecoder(r)
+
+	var header SnapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return header, fmt.Errorf("%w: reading header: %v", ErrInvalidSnapshot, err)
+	}
+	head := header.Head
+	if head.Depth < 1 || head.Depth > maxSnapshotDepth {
+		return header, fmt.Errorf("%w: depth %d out of range", ErrInvalidSnapshot, head.Depth)
+	}
+	if err := checkValue(head.ZeroLeaf); err != nil {
+		return header, fmt.Errorf("%w: zero leaf: %v", ErrInvalidSnapshot, err)
+	}
+	if head.Root == nil {
+		return header, fmt.Errorf("%w: missing root", ErrInvalidSnapshot)
+	}
+	hasher, err := registeredHasher(head.Hasher)
+	if err != nil {
+		return header, err
+	}
+	if onHeader != nil {
+		if err := onHeader(header, hasher); err != nil {
+			return header, err
+		}
+	}
+
+	builder, err := newRootBuilder(head.Depth, head.ZeroLeaf, hasher)
+	if err != nil {
+		return header, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	count := 0
+	for {
+		var leaf SnapshotLeaf
+		if err := decoder.Decode(&leaf); err == io.EOF {
+			break
+		} else if err != nil {
+			return header, fmt.Errorf("%w: reading leaf %d: %v", ErrInvalidSnapshot, count, err)
+		}
+		key, err := getBigPaddedBinaryString(leaf.Index, head.Depth)
+		if err != nil {
+			return header, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		if err := checkValue(leaf.Value); err != nil {
+			return header, fmt.Errorf("%w: leaf %s: %v", ErrInvalidSnapshot, leaf.Index, err)
+		}
+		if err := builder.add(key, leaf.Value); err != nil {
+			return header, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		if onLeaf != nil {
+			onLeaf(key, leaf)
+		}
+		count++
+	}
+
+	if count != header.LeafCount {
+		return header, fmt.Errorf("%w: header declares %d leaves, found %d", ErrInvalidSnapshot, header.LeafCount, count)
+	}
+	root, err := builder.root()
+	if err != nil {
+		return header, err
+	}
+	if root.Cmp(head.Root) != 0 {
+		return header, fmt.Errorf("%w: leaves hash to root %s, header declares %s", ErrInvalidSnapshot, root, head.Root)
+	}
+	return header, nil
+}
+
+// rootBuilder computes the root of a sparse Merkle tree from leaves added in
+// ascending key order, keeping at most one pending subtree per level.
+type rootBuilder struct {
+	depth       int           // Depth of the tree.
+	hasher      Hasher        // Hasher of the inner nodes.
+	emptyHashes []*big.Int    // Hashes of empty subtrees, by height.
+	stack       []pendingNode // Completed subtrees whose parents are not yet complete.
+	last        string        // Key of the last added leaf, or empty.
+}
+
+// pendingNode is a completed subtree held by a rootBuilder.
+type pendingNode struct {
+	prefix string   // Path from the root to the subtree root.
+	hash   *big.Int // Hash of the subtree root.
+}
+
+// newRootBuilder creates a rootBuilder for a tree of the given depth whose
+// inner nodes are hashed with hasher.
+func newRootBuilder(depth int, zeroLeaf *big.Int, hasher Hasher) (*rootBuilder, error) {
+	emptyHashes, err := emptyHashesWith(depth, hasher, zeroLeaf)
+	if err != nil {
+		return nil, err
+	}
+	return &rootBuilder{depth: depth, hasher: hasher, emptyHashes: emptyHashes}, nil
+}
+
+// add adds the leaf with the given binary key, which must be greater than any
+// added before.
+func (b *rootBuilder) add(key string, value *big.Int) error {
+	if b.last != "" && key <= b.last {
+		return fmt.Errorf("leaf %s out of order after %s", key, b.last)
+	}
+	b.last = key
+
+	for len(b.stack) > 0 {
+		top := b.stack[len(b.stack)-1]
+		if strings.HasPrefix(key, top.prefix[:len(top.prefix)-1]) {
+			break
+		}
+		if err := b.lift(); err != nil {
+			return err
+		}
+	}
+	b.stack = append(b.stack, pendingNode{prefix: key, hash: value})
+	return nil
+}
+
+// root completes all pending subtrees and returns the root hash.
+func (b *rootBuilder) root() (*big.Int, error) {
+	if len(b.stack) == 0 {
+		return b.emptyHashes[b.depth], nil
+	}
+	for b.stack[len(b.stack)-1].prefix != "" {
+		if err := b.lift(); err != nil {
+			return nil, err
+		}
+	}
+	return b.stack[0].hash, nil
+}
+
+// lift replaces the topmost pending subtree with its parent, combining it
+// with its left sibling if that is pending too.
+func (b *rootBuilder) lift() error {
+	top := b.stack[len(b.stack)-1]
+	b.stack = b.stack[:len(b.stack)-1]
+	height := b.depth - len(top.prefix)
+	parent := top.prefix[:len(top.prefix)-1]
+
+	left, right := top.hash, b.emptyHashes[height]
+	if top.prefix[len(parent)] == '1' {
+		left, right = b.emptyHashes[height], top.hash
+		if n := len(b.stack); n > 0 && b.stack[n-1].prefix == parent+"0" {
+			left = b.stack[n-1].hash
+			b.stack = b.stack[:n-1]
+		}
+	}
+
+	hash, err := b.hasher.Hash(left, right)
+	if err != nil {
+		return fmt.Errorf("hashing node %q: %w", parent, err)
+	}
+	b.stack = append(b.stack, pendingNode{prefix: parent, hash: hash})
+	return nil
+}
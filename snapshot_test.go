@@ -0,0 +1,148 @@
+package smt
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	smt := NewSparseMerkleTree(10, zeroLeaf)
+	for i := 0; i < 100; i++ {
+		smt.Insert(rng.Intn(1<<10), big.NewInt(rng.Int63()))
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, smt.WriteSnapshot(&buf))
+
+	header, err := ValidateSnapshot(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, len(smt.leaves), header.LeafCount)
+	assert.Equal(t, smt.root.Data, header.Head.Root)
+
+	restored, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, smt.root.Data, restored.root.Data)
+	assert.Equal(t, smt.leaves, restored.leaves)
+}
+
+func TestSnapshotRoundTripAddressTree(t *testing.T) {
+	tree := NewAddressTree()
+	for i := byte(1); i <= 20; i++ {
+		var address [20]byte
+		address[0], address[19] = 0xff-i, i
+		assert.NoError(t, tree.Insert(address[:], big.NewInt(int64(i))))
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, tree.Tree().WriteSnapshot(&buf))
+
+	restored, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, tree.Root(), restored.root.Data)
+	assert.Equal(t, tree.Tree().leaves, restored.leaves)
+}
+
+func TestValidateSnapshotEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NewSparseMerkleTree(4, zeroLeaf).WriteSnapshot(&buf))
+	_, err := ValidateSnapshot(&buf)
+	assert.NoError(t, err)
+}
+
+func TestValidateSnapshotRejects(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	smt.Insert(2, big.NewInt(20))
+	smt.Insert(9, big.NewInt(90))
+	var buf bytes.Buffer
+	assert.NoError(t, smt.WriteSnapshot(&buf))
+	lines := strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	cases := map[string]string{
+		"tampered value": lines[0] + lines[1] + strings.Replace(lines[2], "90", "91", 1),
+		"missing leaf":   lines[0] + lines[1],
+		"out of order":   lines[0] + lines[2] + "\n" + lines[1],
+		"bad header":     "{",
+	}
+	for name, snapshot := range cases {
+		_, err := ValidateSnapshot(strings.NewReader(snapshot))
+		assert.ErrorIs(t, err, ErrInvalidSnapshot, name)
+	}
+}
+
+func TestReadSnapshotHasherMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NewSparseMerkleTree(2, zeroLeaf).WriteSnapshot(&buf))
+	snapshot := strings.Replace(buf.String(), PoseidonHasher.Fingerprint(), "poseidon-bn254-t3-0000000000000000", 1)
+
+	_, err := ReadSnapshot(strings.NewReader(snapshot))
+	assert.ErrorIs(t, err, ErrHasherMismatch)
+}
+
+func TestSnapshotRoundTripRegisteredHasher(t *testing.T) {
+	smt := NewSparseMerkleTree(8, zeroLeaf)
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, smt.Insert(i*11, big.NewInt(int64(i+1))))
+	}
+	migrated, _, err := smt.MigrateHasher(sha256Hasher)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, migrated.WriteSnapshot(&buf))
+	RegisterHasher(sha256Hasher)
+
+	restored, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, migrated.Root(), restored.Root())
+	assert.Equal(t, sha256Hasher.Fingerprint(), restored.Head().Hasher)
+	assert.NoError(t, restored.Insert(1, big.NewInt(1)), "the restored tree keeps hashing with the registered hasher")
+	assert.NoError(t, migrated.Insert(1, big.NewInt(1)))
+	assert.Equal(t, migrated.Root(), restored.Root())
+}
+
+func TestSnapshotDigest(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	indices := rng.Perm(1 << 8)[:50]
+	forward, backward := NewSparseMerkleTree(8, zeroLeaf), NewSparseMerkleTree(8, zeroLeaf)
+	for i, index := range indices {
+		assert.NoError(t, forward.Insert(index, big.NewInt(int64(index))))
+		assert.NoError(t, backward.Insert(indices[len(indices)-1-i], big.NewInt(int64(indices[len(indices)-1-i]))))
+	}
+	assert.NoError(t, backward.Insert(indices[0], big.NewInt(int64(indices[0]))), "versions may differ")
+
+	digest, err := forward.SnapshotDigest()
+	assert.NoError(t, err)
+	other, err := backward.SnapshotDigest()
+	assert.NoError(t, err)
+	assert.Equal(t, digest, other)
+	clone, err := forward.Clone().SnapshotDigest()
+	assert.NoError(t, err)
+	assert.Equal(t, digest, clone)
+
+	assert.NoError(t, backward.Insert(indices[0], big.NewInt(1000)))
+	other, err = backward.SnapshotDigest()
+	assert.NoError(t, err)
+	assert.NotEqual(t, digest, other)
+}
+
+func TestSnapshotDigestAddressTree(t *testing.T) {
+	tree := NewAddressTree()
+	address := bytes.Repeat([]byte{0xab}, 20)
+	assert.NoError(t, tree.Insert(address, big.NewInt(1)))
+
+	digest, err := tree.Tree().SnapshotDigest()
+	assert.NoError(t, err)
+	clone, err := tree.Tree().Clone().SnapshotDigest()
+	assert.NoError(t, err)
+	assert.Equal(t, digest, clone)
+
+	assert.NoError(t, tree.Insert(address, big.NewInt(2)))
+	other, err := tree.Tree().SnapshotDigest()
+	assert.NoError(t, err)
+	assert.NotEqual(t, digest, other)
+}
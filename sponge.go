@@ -0,0 +1,51 @@
+package smt
+
+import (
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// spongeRate is the number of message chunks absorbed per Poseidon call; the
+// remaining input of the 16-input permutation carries the sponge state.
+const spongeRate = 15
+
+// HashLeafBytes absorbs data of any length into a single field element with a
+// Poseidon sponge, for use as a leaf value. The scheme is reproducible in a
+// circuit:
+//
+//  1. Append a 0x01 byte to data, then zero bytes up to a multiple of 31
+//     bytes. The padding makes the encoding injective, so no two inputs,
+//     including the empty one, share padded chunks.
+//  2. Read each 31-byte chunk as a big-endian integer, which is always a
+//     field element.
+//  3. Starting from state 0, for each group of 15 chunks set
+//     state = Poseidon(state, chunk1, ..., chunk15), filling a short last
+//     group with zeros.
+//
+// The final state is the hash.
+func HashLeafBytes(data []byte) (*big.Int, error) {
+	padded := make([]byte, len(data)+1, (len(data)/fieldChunkSize+1)*fieldChunkSize)
+	copy(padded, data)
+	padded[len(data)] = 0x01
+	padded = padded[:cap(padded)]
+
+	chunks := len(padded) / fieldChunkSize
+	state := big.NewInt(0)
+	for start := 0; start < chunks; start += spongeRate {
+		inputs := make([]*big.Int, spongeRate+1)
+		inputs[0] = state
+		for i := 1; i <= spongeRate; i++ {
+			inputs[i] = new(big.Int)
+			if chunk := start + i - 1; chunk < chunks {
+				inputs[i].SetBytes(padded[chunk*fieldChunkSize : (chunk+1)*fieldChunkSize])
+			}
+		}
+
+		var err error
+		if state, err = poseidon.Hash(inputs); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
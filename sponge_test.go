@@ -0,0 +1,44 @@
+package smt
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashLeafBytes(t *testing.T) {
+	// The empty input is a single padding chunk.
+	padding := new(big.Int).Lsh(big.NewInt(1), 30*8)
+	inputs := []*big.Int{big.NewInt(0), padding}
+	for len(inputs) < 16 {
+		inputs = append(inputs, big.NewInt(0))
+	}
+	expected, err := poseidon.Hash(inputs)
+	assert.NoError(t, err)
+	empty, err := HashLeafBytes(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, empty)
+
+	seen := make(map[string][]byte)
+	for _, data := range [][]byte{
+		nil,
+		{0x00},
+		{0xde},
+		{0xde, 0x00},
+		bytes.Repeat([]byte{0xff}, 30),
+		bytes.Repeat([]byte{0xff}, 31),
+		bytes.Repeat([]byte{0xab}, 31*15),
+		bytes.Repeat([]byte{0xab}, 31*15+1),
+		bytes.Repeat([]byte{0xcd}, 1000),
+	} {
+		hash, err := HashLeafBytes(data)
+		assert.NoError(t, err)
+		assert.NoError(t, checkValue(hash))
+		previous, collides := seen[hash.String()]
+		assert.False(t, collides, "%x collides with %x", data, previous)
+		seen[hash.String()] = data
+	}
+}
@@ -0,0 +1,57 @@
+package smt
+
+import "math/big"
+
+// SubtreeProof proves that the subtree at a binary path prefix of a tree,
+// such as a shard, has the given root.
+type SubtreeProof struct {
+	Prefix      string            `json:"prefix"`      // Binary path of the subtree from the tree root, most significant bit first.
+	SubtreeRoot *big.Int          `json:"subtreeRoot"` // Root of the subtree.
+	Path        []*MerklePathItem `json:"path"`        // Merkle path from the subtree root to the tree root.
+}
+
+// SubtreeRoot returns the root of the subtree at the given binary path prefix
+// from the root, the hash of an empty subtree if none of its leaves is set.
+// The empty prefix is the whole tree and a full-length prefix is a leaf.
+func (smt *SparseMerkleTree) SubtreeRoot(prefix string) (*big.Int, error) {
+	roots, err := smt.SubtreeHashes([]string{prefix})
+	if err != nil {
+		return nil, err
+	}
+	return roots[0], nil
+}
+
+// ProveSubtree proves the root of the subtree at the given binary path prefix
+// against the root of the tree.
+func (smt *SparseMerkleTree) ProveSubtree(prefix string) (*SubtreeProof, error) {
+	if err := checkPrefix(prefix, smt.depth); err != nil {
+		return nil, err
+	}
+
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	path := make([]*MerklePathItem, 0, len(prefix))
+	for i := len(prefix) - 1; i >= 0; i-- {
+		sibling := prefix[:i] + "1"
+		if prefix[i] == '1' {
+			sibling = prefix[:i] + "0"
+		}
+		path = append(path, &MerklePathItem{SiblingHash: smt.prefixHash(sibling), IsRight: prefix[i] == '0'})
+	}
+	return &SubtreeProof{Prefix: prefix, SubtreeRoot: smt.prefixHash(prefix), Path: path}, nil
+}
+
+// VerifySubtreeProof verifies that the subtree at the prefix of proof has its
+// root in the Poseidon tree with the given root.
+func VerifySubtreeProof(proof *SubtreeProof, root *big.Int) bool {
+	if proof == nil || proof.SubtreeRoot == nil || root == nil || len(proof.Path) != len(proof.Prefix) {
+		return false
+	}
+	for i, item := range proof.Path {
+		if item == nil || item.IsRight != (proof.Prefix[len(proof.Prefix)-1-i] == '0') {
+			return false
+		}
+	}
+	return VerifyMerklePath(proof.SubtreeRoot, proof.Path, root)
+}
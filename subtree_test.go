@@ -0,0 +1,49 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubtreeRoot(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.Insert(5, big.NewInt(50)))
+	assert.NoError(t, smt.Insert(12, big.NewInt(120)))
+
+	root, err := smt.SubtreeRoot("")
+	assert.NoError(t, err)
+	assert.Equal(t, smt.Root(), root)
+	leaf, err := smt.SubtreeRoot("0101")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(50), leaf)
+	empty, err := smt.SubtreeRoot("00")
+	assert.NoError(t, err)
+	assert.Equal(t, getEmptyHashes(4, zeroLeaf)[2], empty)
+
+	shard := NewSparseMerkleTree(2, zeroLeaf)
+	assert.NoError(t, shard.Insert(0, big.NewInt(120)))
+	shardRoot, err := smt.SubtreeRoot("11")
+	assert.NoError(t, err)
+	assert.Equal(t, shard.Root(), shardRoot, "a subtree root is the root of the shard on its own")
+
+	for _, prefix := range []string{"", "0", "01", "110", "1100", "0000"} {
+		proof, err := smt.ProveSubtree(prefix)
+		assert.NoError(t, err)
+		assert.True(t, VerifySubtreeProof(proof, smt.Root()), prefix)
+	}
+
+	proof, err := smt.ProveSubtree("01")
+	assert.NoError(t, err)
+	proof.Prefix = "10"
+	assert.False(t, VerifySubtreeProof(proof, smt.Root()), "the proof is bound to its prefix")
+	proof.Prefix = "01"
+	proof.SubtreeRoot = big.NewInt(1)
+	assert.False(t, VerifySubtreeProof(proof, smt.Root()))
+
+	_, err = smt.SubtreeRoot("01010")
+	assert.Error(t, err)
+	_, err = smt.ProveSubtree("2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// DeletionProof proves that the leaf at Index holds the tombstone, and so was
+// deleted rather than never inserted.
+type DeletionProof struct {
+	Index int               `json:"index"` // Index of the deleted leaf.
+	Path  []*MerklePathItem `json:"path"`  // Merkle path of the tombstone at Index.
+}
+
+// SetTombstone switches the tree to tombstone mode, where Delete overwrites a
+// leaf with tombstone instead of removing it, so the deletion can be proven
+// with ProveDeletion until the tombstones are purged. Only Delete can write
+// the tombstone; setting it as a leaf value returns ErrInvalidValue. A nil
+// tombstone switches
+// back to removing leaves. Like the zero leaf, the tombstone can only be
+// changed while the tree is empty and returns ErrConfigFrozen otherwise.
+func (smt *SparseMerkleTree) SetTombstone(tombstone *big.Int) error {
+	if tombstone != nil {
+		if err := checkValue(tombstone); err != nil {
+			return err
+		}
+	}
+
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := smt.checkUnfrozen("tombstone"); err != nil {
+		return err
+	}
+	if tombstone != nil && tombstone.Cmp(smt.zeroLeaf) == 0 {
+		return fmt.Errorf("tombstone must differ from the zero leaf")
+	}
+	smt.tombstone = copyInt(tombstone)
+	return nil
+}
+
+// Tombstone returns the leaf written by Delete in tombstone mode, or nil if
+// the tree removes deleted leaves.
+func (smt *SparseMerkleTree) Tombstone() *big.Int {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	return copyInt(smt.tombstone)
+}
+
+// IsDeleted reports whether the leaf at index holds the tombstone.
+func (smt *SparseMerkleTree) IsDeleted(index int) bool {
+	value, exists := smt.Get(index)
+	tombstone := smt.Tombstone()
+	return exists && tombstone != nil && value.Cmp(tombstone) == 0
+}
+
+// entomb overwrites the leaf with the given binary key with the tombstone as
+// a single commit, dropping its preimage, hashed key and key name. The caller
+// must hold the write lock.
+func (smt *SparseMerkleTree) entomb(key string) error {
+	if err := smt.store(key, smt.tombstone); err != nil {
+		return err
+	}
+	smt.commit()
+	return nil
+}
+
+// checkNotTombstone returns an error wrapping ErrInvalidValue if value is the
+// tombstone, so that only Delete can write it and a tombstone always proves
+// a deletion. The caller must hold the lock.
+func (smt *SparseMerkleTree) checkNotTombstone(value *big.Int) error {
+	if smt.tombstone != nil && value != nil && value.Cmp(smt.tombstone) == 0 {
+		return fmt.Errorf("%w: leaf value %s is the tombstone", ErrInvalidValue, value)
+	}
+	return nil
+}
+
+// ProveDeletion returns a proof that the leaf at index was deleted in
+// tombstone mode and not purged since.
+func (smt *SparseMerkleTree) ProveDeletion(index int) (*DeletionProof, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, err
+	}
+	key := getPaddedBinaryString(index, smt.depth)
+	if value, exists := smt.leaves[key]; !exists || smt.tombstone == nil || value.Cmp(smt.tombstone) != 0 {
+		return nil, fmt.Errorf("no tombstone at key: %s", key)
+	}
+	return &DeletionProof{Index: index, Path: smt.generateMerklePath(smt.root, key)}, nil
+}
+
+// VerifyDeletion checks that proof shows the leaf at its index to hold
+// tombstone in the tree with the given root.
+func VerifyDeletion(proof *DeletionProof, tombstone, root *big.Int) bool {
+	return proof != nil && verifyClaimPath(proof.Index, tombstone, proof.Path, root)
+}
+
+// Purge removes every tombstone in a single commit, so the purged indices
+// become indistinguishable from ones never inserted, and returns the number
+// of tombstones removed. It does not commit if there are none.
+func (smt *SparseMerkleTree) Purge() int {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if smt.tombstone == nil {
+		return 0
+	}
+	var keys []string
+	for key, value := range smt.leaves {
+		if value.Cmp(smt.tombstone) == 0 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return 0
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		smt.delete(key)
+	}
+	smt.commit()
+	return len(keys)
+}
@@ -0,0 +1,75 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTombstone(t *testing.T) {
+	tombstone := big.NewInt(0xdead)
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.SetTombstone(tombstone))
+	assert.Error(t, smt.SetTombstone(zeroLeaf))
+	empty := smt.Root()
+
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	assert.ErrorIs(t, smt.SetTombstone(nil), ErrConfigFrozen)
+
+	assert.NoError(t, smt.Delete(3))
+	assert.True(t, smt.IsDeleted(3))
+	assert.NotEqual(t, empty, smt.Root(), "a tombstone is not silent absence")
+	version := smt.Head().Version
+	assert.NoError(t, smt.Delete(3))
+	assert.Equal(t, version, smt.Head().Version, "deleting a tombstone is a no-op")
+
+	proof, err := smt.ProveDeletion(3)
+	assert.NoError(t, err)
+	assert.True(t, VerifyDeletion(proof, tombstone, smt.Root()))
+	assert.False(t, VerifyDeletion(proof, zeroLeaf, smt.Root()))
+	_, err = smt.ProveDeletion(4)
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, smt.Purge())
+	assert.Equal(t, empty, smt.Root())
+	assert.False(t, smt.IsDeleted(3))
+	assert.Equal(t, 0, smt.Purge())
+}
+
+func TestTombstoneDisabled(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	empty := smt.Root()
+	assert.Nil(t, smt.Tombstone())
+
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	assert.NoError(t, smt.Delete(3))
+	assert.Equal(t, empty, smt.Root())
+	assert.False(t, smt.IsDeleted(3))
+	assert.Equal(t, 0, smt.Purge())
+}
+
+func TestTombstoneForgery(t *testing.T) {
+	tombstone := big.NewInt(0xdead)
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	assert.NoError(t, smt.SetTombstone(tombstone))
+
+	assert.ErrorIs(t, smt.Insert(5, tombstone), ErrInvalidValue)
+	assert.NoError(t, smt.Insert(6, big.NewInt(60)))
+	root := smt.Root()
+	_, _, err := smt.Update(6, tombstone)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, err = smt.BatchInsert([]LeafUpdate{{Index: 5, Value: tombstone}})
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, err = smt.ApplyAtomic([]Mutation{{Index: 5, Value: tombstone}})
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, err = smt.ApplyParallel([]Mutation{{Index: 5, Value: tombstone}}, 0)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, err = smt.InsertHashed(KeyDomainBytes, []byte("alice"), tombstone)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+
+	assert.Equal(t, root, smt.Root())
+	assert.False(t, smt.IsDeleted(5))
+	_, err = smt.ProveDeletion(5)
+	assert.Error(t, err, "only Delete writes a provable tombstone")
+}
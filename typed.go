@@ -0,0 +1,144 @@
+package smt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// maxPreimageElements is the largest preimage Poseidon hashes in one call.
+const maxPreimageElements = 16
+
+// fieldChunkSize is the number of bytes packed into one field element, small
+// enough that every chunk is below the field modulus.
+const fieldChunkSize = 31
+
+// ValueEncoding converts values of type V to and from the field elements
+// hashed into a leaf. Encode must return at most 16 elements.
+type ValueEncoding[V any] interface {
+	Encode(value V) ([]*big.Int, error)
+	Decode(elements []*big.Int) (V, error)
+}
+
+// JSONEncoding encodes values as their JSON representation, packed into
+// field elements 31 bytes at a time after a length element. Values whose
+// JSON exceeds 465 bytes cannot be encoded.
+type JSONEncoding[V any] struct{}
+
+// Encode encodes value as packed JSON.
+func (JSONEncoding[V]) Encode(value V) ([]*big.Int, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return packBytes(data)
+}
+
+// Decode decodes packed JSON into a value.
+func (JSONEncoding[V]) Decode(elements []*big.Int) (V, error) {
+	var value V
+	data, err := unpackBytes(elements)
+	if err != nil {
+		return value, err
+	}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// packBytes packs data into a length element followed by 31-byte chunks.
+func packBytes(data []byte) ([]*big.Int, error) {
+	chunks := (len(data) + fieldChunkSize - 1) / fieldChunkSize
+	if chunks+1 > maxPreimageElements {
+		return nil, fmt.Errorf("value too large: %d bytes, at most %d", len(data), (maxPreimageElements-1)*fieldChunkSize)
+	}
+
+	elements := make([]*big.Int, 0, chunks+1)
+	elements = append(elements, big.NewInt(int64(len(data))))
+	for start := 0; start < len(data); start += fieldChunkSize {
+		end := start + fieldChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		elements = append(elements, new(big.Int).SetBytes(data[start:end]))
+	}
+	return elements, nil
+}
+
+// unpackBytes reverses packBytes.
+func unpackBytes(elements []*big.Int) ([]byte, error) {
+	if len(elements) == 0 || !elements[0].IsInt64() {
+		return nil, fmt.Errorf("missing length element")
+	}
+	length := int(elements[0].Int64())
+	if length < 0 || (length+fieldChunkSize-1)/fieldChunkSize != len(elements)-1 {
+		return nil, fmt.Errorf("length %d does not match %d chunks", length, len(elements)-1)
+	}
+
+	data := make([]byte, 0, length)
+	for i, element := range elements[1:] {
+		size := fieldChunkSize
+		if i == len(elements)-2 {
+			size = length - i*fieldChunkSize
+		}
+		if element.Sign() < 0 || element.BitLen() > 8*size {
+			return nil, fmt.Errorf("chunk %d does not fit in %d bytes", i, size)
+		}
+		data = append(data, element.FillBytes(make([]byte, size))...)
+	}
+	return data, nil
+}
+
+// TypedTree is a sparse Merkle tree whose leaves hold values of type V. Each
+// leaf is the Poseidon hash of the encoded value, which is recorded as the
+// leaf preimage so the value can be read back and opened.
+type TypedTree[V any] struct {
+	tree     *SparseMerkleTree
+	encoding ValueEncoding[V]
+}
+
+// NewTypedTree creates a typed tree of the given depth using encoding to
+// convert values to field elements.
+func NewTypedTree[V any](depth int, zeroLeaf *big.Int, encoding ValueEncoding[V]) *TypedTree[V] {
+	return &TypedTree[V]{tree: NewSparseMerkleTree(depth, zeroLeaf), encoding: encoding}
+}
+
+// Tree returns the underlying sparse Merkle tree.
+func (t *TypedTree[V]) Tree() *SparseMerkleTree {
+	return t.tree
+}
+
+// Root returns the root hash of the tree.
+func (t *TypedTree[V]) Root() *big.Int {
+	return t.tree.Root()
+}
+
+// Insert encodes value and inserts its hash at index. It returns the leaf
+// hash.
+func (t *TypedTree[V]) Insert(index int, value V) (*big.Int, error) {
+	elements, err := t.encoding.Encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode value: %w", err)
+	}
+	return t.tree.InsertPreimage(index, elements)
+}
+
+// Get returns the decoded value at index, if one was inserted through the
+// typed tree and not overwritten since.
+func (t *TypedTree[V]) Get(index int) (V, bool, error) {
+	var value V
+	elements, exists := t.tree.preimage(index)
+	if !exists {
+		return value, false, nil
+	}
+	value, err := t.encoding.Decode(elements)
+	if err != nil {
+		return value, false, fmt.Errorf("cannot decode value: %w", err)
+	}
+	return value, true, nil
+}
+
+// Open returns the encoded value and Merkle path of the leaf at index, which
+// can be checked with VerifyOpening.
+func (t *TypedTree[V]) Open(index int) (*Opening, error) {
+	return t.tree.Open(index)
+}
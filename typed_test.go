@@ -0,0 +1,59 @@
+package smt
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	Owner   string `json:"owner"`
+	Balance uint64 `json:"balance"`
+}
+
+func TestTypedTree(t *testing.T) {
+	tree := NewTypedTree[account](8, zeroLeaf, JSONEncoding[account]{})
+
+	alice := account{Owner: "alice", Balance: 100}
+	leaf, err := tree.Insert(3, alice)
+	assert.NoError(t, err)
+
+	value, ok, err := tree.Get(3)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, alice, value)
+
+	opening, err := tree.Open(3)
+	assert.NoError(t, err)
+	assert.True(t, VerifyOpening(opening, tree.Root()))
+	assert.Equal(t, leaf, tree.Tree().leaves[getPaddedBinaryString(3, 8)])
+
+	_, ok, err = tree.Get(4)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// A plain insert drops the typed value.
+	assert.NoError(t, tree.Tree().Insert(3, big.NewInt(1)))
+	_, ok, _ = tree.Get(3)
+	assert.False(t, ok)
+}
+
+func TestJSONEncodingLimits(t *testing.T) {
+	encoding := JSONEncoding[string]{}
+
+	for _, s := range []string{"", "x", strings.Repeat("a", 29), strings.Repeat("b", 300)} {
+		elements, err := encoding.Encode(s)
+		assert.NoError(t, err)
+		decoded, err := encoding.Decode(elements)
+		assert.NoError(t, err)
+		assert.Equal(t, s, decoded)
+	}
+
+	_, err := encoding.Encode(strings.Repeat("c", 500))
+	assert.Error(t, err)
+
+	_, err = encoding.Decode([]*big.Int{big.NewInt(40), big.NewInt(1)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// ProofCodec converts Merkle paths to and from a wire format.
+type ProofCodec interface {
+	EncodeProof(path []*PathItem) ([]byte, error)
+	DecodeProof(data []byte) ([]*PathItem, error)
+}
+
+// BinaryItemSize is the size of an encoded path item: a position byte
+// followed by the 32-byte big-endian sibling hash.
+const BinaryItemSize = 33
+
+// BinaryCodec encodes each path item, from the leaf up, in the format of
+// PathItem.MarshalBinary.
+type BinaryCodec struct{}
+
+// EncodeProof implements ProofCodec.
+func (BinaryCodec) EncodeProof(path []*PathItem) ([]byte, error) {
+	data := make([]byte, 0, len(path)*BinaryItemSize)
+	for i, item := range path {
+		if item == nil {
+			return nil, fmt.Errorf("path item %d has no valid sibling hash", i)
+		}
+		encoded, err := item.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("path item %d: %w", i, err)
+		}
+		data = append(data, encoded...)
+	}
+	return data, nil
+}
+
+// DecodeProof implements ProofCodec.
+func (BinaryCodec) DecodeProof(data []byte) ([]*PathItem, error) {
+	if len(data)%BinaryItemSize != 0 {
+		return nil, fmt.Errorf("invalid binary proof length: %d", len(data))
+	}
+
+	path := make([]*PathItem, 0, len(data)/BinaryItemSize)
+	for offset := 0; offset < len(data); offset += BinaryItemSize {
+		item := &PathItem{}
+		if err := item.UnmarshalBinary(data[offset : offset+BinaryItemSize]); err != nil {
+			return nil, fmt.Errorf("path item at offset %d: %w", offset, err)
+		}
+		path = append(path, item)
+	}
+	return path, nil
+}
+
+// MarshalBinary encodes the item as one byte that is 1 if the sibling is a
+// right child and 0 otherwise, followed by the sibling hash as 32 big-endian
+// bytes.
+func (item *PathItem) MarshalBinary() ([]byte, error) {
+	if item.SiblingHash == nil || !utils.CheckBigIntInField(item.SiblingHash) {
+		return nil, fmt.Errorf("no valid sibling hash")
+	}
+	data := make([]byte, BinaryItemSize)
+	if item.IsRight {
+		data[0] = 1
+	}
+	item.SiblingHash.FillBytes(data[1:])
+	return data, nil
+}
+
+// UnmarshalBinary decodes an item encoded by MarshalBinary.
+func (item *PathItem) UnmarshalBinary(data []byte) error {
+	if len(data) != BinaryItemSize {
+		return fmt.Errorf("invalid binary path item length: %d", len(data))
+	}
+	if data[0] > 1 {
+		return fmt.Errorf("invalid position byte %d", data[0])
+	}
+	hash := new(big.Int).SetBytes(data[1:])
+	if !utils.CheckBigIntInField(hash) {
+		return fmt.Errorf("sibling hash is not a field element")
+	}
+	*item = PathItem{SiblingHash: hash, IsRight: data[0] == 1}
+	return nil
+}
+
+// JSONCodec encodes a path as a JSON array of path items.
+type JSONCodec struct{}
+
+// EncodeProof implements ProofCodec.
+func (JSONCodec) EncodeProof(path []*PathItem) ([]byte, error) {
+	return json.Marshal(path)
+}
+
+// DecodeProof implements ProofCodec.
+func (JSONCodec) DecodeProof(data []byte) ([]*PathItem, error) {
+	var path []*PathItem
+	if err := json.Unmarshal(data, &path); err != nil {
+		return nil, err
+	}
+	for i, item := range path {
+		if item == nil || item.SiblingHash == nil {
+			return nil, fmt.Errorf("path item %d has no sibling hash", i)
+		}
+	}
+	return path, nil
+}
+
+// circomProof is the input layout of circom Merkle inclusion circuits.
+type circomProof struct {
+	Siblings    []string `json:"siblings"`
+	PathIndices []int    `json:"pathIndices"`
+}
+
+// CircomCodec encodes a path as the siblings and pathIndices signals used by
+// circom Merkle inclusion circuits, from the leaf up. A path index is 0 when
+// the current node is a left child and 1 when it is a right child.
+type CircomCodec struct{}
+
+// EncodeProof implements ProofCodec.
+func (CircomCodec) EncodeProof(path []*PathItem) ([]byte, error) {
+	proof := circomProof{Siblings: make([]string, len(path)), PathIndices: PathIndices(path)}
+	for i, item := range path {
+		if item == nil || item.SiblingHash == nil {
+			return nil, fmt.Errorf("path item %d has no sibling hash", i)
+		}
+		proof.Siblings[i] = item.SiblingHash.String()
+	}
+	return json.Marshal(proof)
+}
+
+// DecodeProof implements ProofCodec.
+func (CircomCodec) DecodeProof(data []byte) ([]*PathItem, error) {
+	var proof circomProof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return nil, err
+	}
+	if len(proof.Siblings) != len(proof.PathIndices) {
+		return nil, fmt.Errorf("circom proof has %d siblings but %d path indices", len(proof.Siblings), len(proof.PathIndices))
+	}
+
+	path := make([]*PathItem, len(proof.Siblings))
+	for i, sibling := range proof.Siblings {
+		hash, ok := new(big.Int).SetString(sibling, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid sibling %d: %q", i, sibling)
+		}
+		if proof.PathIndices[i] != 0 && proof.PathIndices[i] != 1 {
+			return nil, fmt.Errorf("invalid path index %d: %d", i, proof.PathIndices[i])
+		}
+		path[i] = &PathItem{SiblingHash: hash, IsRight: proof.PathIndices[i] == 0}
+	}
+	return path, nil
+}
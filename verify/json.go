@@ -0,0 +1,106 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// HashFormat selects how hashes are written in the JSON form of path items.
+type HashFormat int
+
+// Hash formats of JSON path items.
+const (
+	HashDecimal HashFormat = iota // Decimal strings, such as "1234".
+	HashHex                       // 0x-prefixed hexadecimal strings, such as "0x4d2".
+)
+
+// FormattedPath is a Merkle path whose JSON form has its hashes in Format, for
+// consumers that expect hexadecimal hashes. A plain path is written with
+// decimal hashes; decoding accepts every format regardless.
+type FormattedPath struct {
+	Path   []*PathItem
+	Format HashFormat
+}
+
+// MarshalJSON encodes the path as an array of path items with their hashes
+// in p.Format.
+func (p FormattedPath) MarshalJSON() ([]byte, error) {
+	items := make([]json.RawMessage, len(p.Path))
+	for i, item := range p.Path {
+		if item == nil {
+			items[i] = json.RawMessage("null")
+			continue
+		}
+		encoded, err := item.marshalJSON(p.Format)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = encoded
+	}
+	return json.Marshal(items)
+}
+
+// jsonPathItem is the JSON form of a path item. Hashes are strings, since
+// JavaScript numbers cannot hold them.
+type jsonPathItem struct {
+	SiblingHash json.RawMessage `json:"siblingHash"`
+	IsRight     bool            `json:"isRight"`
+}
+
+// MarshalJSON encodes the item as {"siblingHash": "...", "isRight": ...}
+// with the hash as a decimal string. Wrap a path in FormattedPath to write
+// hexadecimal hashes.
+func (item PathItem) MarshalJSON() ([]byte, error) {
+	return item.marshalJSON(HashDecimal)
+}
+
+// marshalJSON encodes the item with its hash in format.
+func (item PathItem) marshalJSON(format HashFormat) ([]byte, error) {
+	if item.SiblingHash == nil {
+		return json.Marshal(jsonPathItem{SiblingHash: json.RawMessage("null"), IsRight: item.IsRight})
+	}
+	hash := item.SiblingHash.String()
+	if format == HashHex {
+		hash = "0x" + item.SiblingHash.Text(16)
+	}
+	encoded, err := json.Marshal(hash)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonPathItem{SiblingHash: encoded, IsRight: item.IsRight})
+}
+
+// UnmarshalJSON decodes an item whose hash is a decimal string, a
+// 0x-prefixed hexadecimal string or a JSON number.
+func (item *PathItem) UnmarshalJSON(data []byte) error {
+	var encoded jsonPathItem
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	if len(encoded.SiblingHash) == 0 || string(encoded.SiblingHash) == "null" {
+		*item = PathItem{IsRight: encoded.IsRight}
+		return nil
+	}
+
+	text := string(encoded.SiblingHash)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(encoded.SiblingHash, &text); err != nil {
+			return err
+		}
+	}
+	hash, ok := new(big.Int), false
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		hash, ok = hash.SetString(text[2:], 16)
+	} else {
+		hash, ok = hash.SetString(text, 10)
+	}
+	if !ok || hash.Sign() < 0 || !utils.CheckBigIntInField(hash) {
+		return fmt.Errorf("invalid sibling hash: %s", encoded.SiblingHash)
+	}
+	*item = PathItem{SiblingHash: hash, IsRight: encoded.IsRight}
+	return nil
+}
@@ -0,0 +1,43 @@
+package verify
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathItemJSON(t *testing.T) {
+	item := PathItem{SiblingHash: big.NewInt(1234), IsRight: true}
+
+	data, err := json.Marshal(item)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"siblingHash":"1234","isRight":true}`, string(data))
+
+	data, err = json.Marshal(FormattedPath{Path: []*PathItem{&item}, Format: HashHex})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"siblingHash":"0x4d2","isRight":true}]`, string(data))
+	data, err = json.Marshal([]*PathItem{&item})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"siblingHash":"1234","isRight":true}]`, string(data), "the format only applies to the wrapped path")
+
+	var path []*PathItem
+	assert.NoError(t, json.Unmarshal([]byte(`[{"siblingHash":"0x4d2","isRight":true}]`), &path))
+	assert.Equal(t, []*PathItem{&item}, path)
+
+	for _, encoded := range []string{
+		`{"siblingHash":"1234","isRight":true}`,
+		`{"siblingHash":"0x4d2","isRight":true}`,
+		`{"siblingHash":1234,"isRight":true}`,
+		`{"SiblingHash":1234,"IsRight":true}`,
+	} {
+		var decoded PathItem
+		assert.NoError(t, json.Unmarshal([]byte(encoded), &decoded), encoded)
+		assert.Equal(t, item, decoded, encoded)
+	}
+
+	var decoded PathItem
+	assert.Error(t, json.Unmarshal([]byte(`{"siblingHash":"0xzz"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"siblingHash":"-1"}`), &decoded))
+}
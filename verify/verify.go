@@ -0,0 +1,91 @@
+/*
+Package verify checks sparse Merkle tree proofs produced by package smt.
+
+It depends only on the Poseidon hash and the standard library, so light
+clients and contract simulators can verify paths and decode proofs without
+building the tree, its publishers or its queues.
+*/
+package verify
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// PathItem is an item of a Merkle path.
+type PathItem struct {
+	SiblingHash *big.Int // Hash of the sibling in the Merkle Path.
+	IsRight     bool     // Indicates whether this sibling node is a right child.
+}
+
+// ComputeRootFromPath hashes leaf up the given leaf-to-root path and returns
+// the resulting root.
+func ComputeRootFromPath(leaf *big.Int, path []*PathItem) (*big.Int, error) {
+	if leaf == nil {
+		return nil, fmt.Errorf("nil leaf")
+	}
+
+	current := leaf
+	for i, item := range path {
+		if item == nil || item.SiblingHash == nil {
+			return nil, fmt.Errorf("path item %d has no sibling hash", i)
+		}
+
+		var err error
+		if item.IsRight {
+			current, err = poseidon.Hash([]*big.Int{current, item.SiblingHash})
+		} else {
+			current, err = poseidon.Hash([]*big.Int{item.SiblingHash, current})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hashing path item %d: %w", i, err)
+		}
+	}
+	return current, nil
+}
+
+// VerifyMerklePath reports whether path leads from leaf to expectedRoot.
+func VerifyMerklePath(leaf *big.Int, path []*PathItem, expectedRoot *big.Int) bool {
+	root, err := ComputeRootFromPath(leaf, path)
+	return err == nil && expectedRoot != nil && root.Cmp(expectedRoot) == 0
+}
+
+// VerifyMerklePathAt reports whether path leads from leaf to expectedRoot and
+// is the path of the leaf at index, so a valid path for another index is
+// rejected.
+func VerifyMerklePathAt(index int, leaf *big.Int, path []*PathItem, expectedRoot *big.Int) bool {
+	return PathMatchesIndex(path, index) && VerifyMerklePath(leaf, path, expectedRoot)
+}
+
+// PathMatchesIndex reports whether the sibling positions of a leaf-to-root
+// path are those of the leaf at the given index.
+func PathMatchesIndex(path []*PathItem, index int) bool {
+	if index < 0 || (len(path) < 63 && index >= 1<<len(path)) {
+		return false
+	}
+	for i, item := range path {
+		if item == nil {
+			return false
+		}
+		isLeft := (index>>i)&1 == 0
+		if item.IsRight != isLeft {
+			return false
+		}
+	}
+	return true
+}
+
+// PathIndices returns the path bits of a leaf-to-root path, ordered like its
+// siblings: 1 where the path node is a right child, so its sibling is on the
+// left, and 0 otherwise. A nil item yields 0.
+func PathIndices(path []*PathItem) []int {
+	indices := make([]int, len(path))
+	for i, item := range path {
+		if item != nil && !item.IsRight {
+			indices[i] = 1
+		}
+	}
+	return indices
+}
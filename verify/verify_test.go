@@ -0,0 +1,37 @@
+package verify
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeRootFromPath(t *testing.T) {
+	leaf, sibling, uncle := big.NewInt(1), big.NewInt(2), big.NewInt(3)
+	parent, _ := poseidon.Hash([]*big.Int{sibling, leaf})
+	root, _ := poseidon.Hash([]*big.Int{parent, uncle})
+
+	// Leaf 1 of a depth-2 tree: a right child whose parent is a left child.
+	path := []*PathItem{{SiblingHash: sibling, IsRight: false}, {SiblingHash: uncle, IsRight: true}}
+	computed, err := ComputeRootFromPath(leaf, path)
+	assert.NoError(t, err)
+	assert.Equal(t, root, computed)
+
+	assert.True(t, VerifyMerklePath(leaf, path, root))
+	assert.True(t, VerifyMerklePathAt(1, leaf, path, root))
+	assert.False(t, VerifyMerklePathAt(2, leaf, path, root))
+	assert.False(t, VerifyMerklePath(big.NewInt(4), path, root))
+
+	_, err = ComputeRootFromPath(leaf, []*PathItem{{}})
+	assert.Error(t, err)
+	assert.False(t, VerifyMerklePath(leaf, []*PathItem{nil}, root))
+}
+
+func TestPathIndices(t *testing.T) {
+	// Leaf 1 of a depth-2 tree: a right child whose parent is a left child.
+	path := []*PathItem{{SiblingHash: big.NewInt(2), IsRight: false}, {SiblingHash: big.NewInt(3), IsRight: true}}
+	assert.Equal(t, []int{1, 0}, PathIndices(path))
+	assert.Empty(t, PathIndices(nil))
+}
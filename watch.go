@@ -0,0 +1,96 @@
+package smt
+
+import "math/big"
+
+// ProofUpdate is sent to the subscribers of WatchLeafWithProof after a
+// commit. It carries a fresh proof of the watched leaf if the commit changed
+// the root, and is a heartbeat otherwise.
+type ProofUpdate struct {
+	Head  TreeHead          // Head of the commit.
+	Index int               // Index of the watched leaf.
+	Leaf  *big.Int          // Value of the leaf, the zero leaf if unset.
+	Path  []*MerklePathItem // Merkle path of the leaf, or nil for a heartbeat.
+}
+
+// Heartbeat reports whether the update carries no new proof, because the
+// last proof sent is still valid.
+func (u ProofUpdate) Heartbeat() bool {
+	return u.Path == nil
+}
+
+// leafWatcher is a subscriber of WatchLeafWithProof.
+type leafWatcher struct {
+	key      string           // Binary key of the watched leaf.
+	index    int              // Index of the watched leaf.
+	lastRoot *big.Int         // Root the last proof sent verifies against.
+	updates  chan ProofUpdate // Channel holding at most the latest update.
+}
+
+// WatchLeafWithProof subscribes to proofs of the leaf at index. The returned
+// channel first receives a proof against the current root, then one update
+// per commit: a regenerated proof when the root changed, or a heartbeat when
+// it did not. Every leaf lies in a sibling subtree of every other path, so
+// any change to the root invalidates the proof. The channel holds only the
+// latest update; a slow reader skips the superseded ones. Calling the
+// returned function ends the subscription and closes the channel.
+func (smt *SparseMerkleTree) WatchLeafWithProof(index int) (<-chan ProofUpdate, func(), error) {
+	smt.mu.Lock()
+	defer smt.mu.Unlock()
+
+	if err := checkIndex(index, smt.depth); err != nil {
+		return nil, nil, err
+	}
+	w := &leafWatcher{key: getPaddedBinaryString(index, smt.depth), index: index, updates: make(chan ProofUpdate, 1)}
+	smt.notifyWatcher(w, smt.head())
+	smt.watchers = append(smt.watchers, w)
+
+	cancel := func() {
+		smt.mu.Lock()
+		defer smt.mu.Unlock()
+
+		for i, other := range smt.watchers {
+			if other == w {
+				smt.watchers = append(smt.watchers[:i], smt.watchers[i+1:]...)
+				close(w.updates)
+				return
+			}
+		}
+	}
+	return w.updates, cancel, nil
+}
+
+// notifyWatchers sends head to every watcher. The caller must hold the write
+// lock.
+func (smt *SparseMerkleTree) notifyWatchers(head TreeHead) {
+	for _, w := range smt.watchers {
+		smt.notifyWatcher(w, head)
+	}
+}
+
+// notifyWatcher sends w a proof against head, or a heartbeat if the last
+// proof sent still verifies against it, replacing any update w has not read
+// yet. The caller must hold the write lock.
+func (smt *SparseMerkleTree) notifyWatcher(w *leafWatcher, head TreeHead) {
+	update := ProofUpdate{Head: head, Index: w.index, Leaf: smt.leafOrZero(w.key)}
+	if w.lastRoot == nil || w.lastRoot.Cmp(head.Root) != 0 {
+		update.Path = smt.generateMerklePath(smt.root, w.key)
+		w.lastRoot = head.Root
+	}
+
+	for {
+		select {
+		case w.updates <- update:
+			return
+		default:
+		}
+		select {
+		case stale := <-w.updates:
+			// Keep a dropped proof if the replacement is a heartbeat, so the
+			// reader still learns the path it relies on.
+			if update.Path == nil {
+				update.Path = stale.Path
+			}
+		default:
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchLeafWithProof(t *testing.T) {
+	smt := NewSparseMerkleTree(4, zeroLeaf)
+	updates, cancel, err := smt.WatchLeafWithProof(3)
+	assert.NoError(t, err)
+
+	initial := <-updates
+	assert.False(t, initial.Heartbeat())
+	assert.Equal(t, zeroLeaf, initial.Leaf)
+	assert.True(t, VerifyMerklePath(initial.Leaf, initial.Path, smt.Root()))
+
+	assert.NoError(t, smt.Insert(9, big.NewInt(90)))
+	update := <-updates
+	assert.False(t, update.Heartbeat(), "a change elsewhere moves a sibling of the path")
+	assert.True(t, VerifyMerklePath(update.Leaf, update.Path, smt.Root()))
+
+	assert.NoError(t, smt.Insert(9, big.NewInt(90)))
+	heartbeat := <-updates
+	assert.True(t, heartbeat.Heartbeat())
+	assert.Equal(t, smt.Head().Version, heartbeat.Head.Version)
+
+	// A slow reader only sees the latest update.
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	assert.NoError(t, smt.Insert(3, big.NewInt(30)))
+	latest := <-updates
+	assert.Equal(t, smt.Head().Version, latest.Head.Version)
+	assert.False(t, latest.Heartbeat(), "a skipped proof is kept over a heartbeat")
+	assert.Equal(t, big.NewInt(30), latest.Leaf)
+	assert.True(t, VerifyMerklePath(latest.Leaf, latest.Path, smt.Root()))
+
+	cancel()
+	_, open := <-updates
+	assert.False(t, open)
+	assert.NoError(t, smt.Insert(1, big.NewInt(10)))
+	cancel()
+
+	_, _, err = smt.WatchLeafWithProof(16)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
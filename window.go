@@ -0,0 +1,77 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// VerifyAgainstAny verifies claim against each of roots in order and returns
+// the first root it holds against. zeroLeaf is passed to Verify.
+func VerifyAgainstAny(claim Claim, roots []*big.Int, zeroLeaf *big.Int) (*big.Int, error) {
+	for _, root := range roots {
+		if root != nil && Verify(claim, root, zeroLeaf) == nil {
+			return root, nil
+		}
+	}
+	return nil, fmt.Errorf("claim does not hold against any of %d roots", len(roots))
+}
+
+// RootWindow keeps a sliding window of the most recently published roots, so
+// clients can accept proofs generated shortly before a root rotation.
+type RootWindow struct {
+	mu    sync.Mutex
+	size  int
+	roots []*big.Int // Published roots, oldest first.
+}
+
+// NewRootWindow creates a window holding at most size roots.
+func NewRootWindow(size int) *RootWindow {
+	if size < 1 {
+		size = 1
+	}
+	return &RootWindow{size: size}
+}
+
+// Publish adds root as the latest root, evicting the oldest root if the
+// window is full. Publishing the current latest root again has no effect.
+func (w *RootWindow) Publish(root *big.Int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n := len(w.roots); n > 0 && w.roots[n-1].Cmp(root) == 0 {
+		return
+	}
+	w.roots = append(w.roots, root)
+	if len(w.roots) > w.size {
+		w.roots = w.roots[len(w.roots)-w.size:]
+	}
+}
+
+// Roots returns the roots in the window, newest first.
+func (w *RootWindow) Roots() []*big.Int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	roots := make([]*big.Int, len(w.roots))
+	for i, root := range w.roots {
+		roots[len(w.roots)-1-i] = root
+	}
+	return roots
+}
+
+// Contains reports whether root is in the window.
+func (w *RootWindow) Contains(root *big.Int) bool {
+	for _, r := range w.Roots() {
+		if r.Cmp(root) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify verifies claim against the roots in the window, newest first, and
+// returns the root it holds against. zeroLeaf is passed to Verify.
+func (w *RootWindow) Verify(claim Claim, zeroLeaf *big.Int) (*big.Int, error) {
+	return VerifyAgainstAny(claim, w.Roots(), zeroLeaf)
+}
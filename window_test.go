@@ -0,0 +1,38 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootWindow(t *testing.T) {
+	smt := NewSparseMerkleTree(3, zeroLeaf)
+	window := NewRootWindow(2)
+
+	smt.Insert(1, big.NewInt(1))
+	window.Publish(smt.root.Data)
+	claim, err := smt.MembershipClaim(1)
+	assert.NoError(t, err)
+	oldRoot := smt.root.Data
+
+	smt.Insert(2, big.NewInt(2))
+	window.Publish(smt.root.Data)
+	window.Publish(smt.root.Data)
+	assert.Len(t, window.Roots(), 2)
+	assert.Equal(t, smt.root.Data, window.Roots()[0])
+
+	root, err := window.Verify(claim, zeroLeaf)
+	assert.NoError(t, err)
+	assert.Equal(t, oldRoot, root, "proofs from before the rotation are accepted")
+
+	smt.Insert(3, big.NewInt(3))
+	window.Publish(smt.root.Data)
+	assert.False(t, window.Contains(oldRoot))
+	_, err = window.Verify(claim, zeroLeaf)
+	assert.Error(t, err)
+
+	_, err = VerifyAgainstAny(claim, nil, zeroLeaf)
+	assert.Error(t, err)
+}
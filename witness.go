@@ -0,0 +1,190 @@
+package smt
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Witness holds the minimal part of a tree needed to recompute its root after
+// updating a known set of leaves, without access to the rest of the tree.
+type Witness struct {
+	Depth    int                 // The depth of the tree the witness was extracted from.
+	ZeroLeaf *big.Int            // Hash of the zero leaf.
+	Hasher   string              // Fingerprint of the node hasher, see Hasher.Fingerprint. Empty means PoseidonHasher.
+	Leaves   map[int]*big.Int    // Current values of the touched leaves, by index.
+	Nodes    map[string]*big.Int // Hashes of non-empty untouched subtrees next to the touched paths, keyed by binary path prefix.
+
+	hasher Hasher // Hasher of the tree the witness was extracted from, if known.
+}
+
+// ExtractWitness returns the witness needed to re-execute updates to the
+// leaves at the given indices statelessly. Subtrees that are empty are
+// omitted, since their hashes follow from the zero leaf. It returns
+// ErrIndexOutOfRange if an index does not address a leaf of the tree.
+func (smt *SparseMerkleTree) ExtractWitness(indices []int) (*Witness, error) {
+	smt.mu.RLock()
+	defer smt.mu.RUnlock()
+
+	w := &Witness{
+		Depth:    smt.depth,
+		ZeroLeaf: smt.zeroLeaf,
+		Hasher:   smt.hasher.Fingerprint(),
+		Leaves:   make(map[int]*big.Int),
+		Nodes:    make(map[string]*big.Int),
+		hasher:   smt.hasher,
+	}
+
+	touched := make(map[string]bool)
+	for _, index := range indices {
+		if err := checkIndex(index, smt.depth); err != nil {
+			return nil, err
+		}
+		key := getPaddedBinaryString(index, smt.depth)
+		for depth := 0; depth <= smt.depth; depth++ {
+			touched[key[:depth]] = true
+		}
+
+		w.Leaves[index] = smt.zeroLeaf
+		if value, exists := smt.leaves[key]; exists {
+			w.Leaves[index] = value
+		}
+	}
+
+	for prefix := range touched {
+		if len(prefix) == smt.depth {
+			continue
+		}
+		for _, bit := range []string{"0", "1"} {
+			child := prefix + bit
+			if touched[child] {
+				continue
+			}
+			if node := smt.nodeAt(child); node != nil {
+				w.Nodes[child] = node.Data
+			}
+		}
+	}
+
+	return w, nil
+}
+
+// Root recomputes the root of the tree the witness was extracted from.
+func (w *Witness) Root() *big.Int {
+	root, _ := ApplyOnWitness(w, nil)
+	return root
+}
+
+// ApplyOnWitness recomputes the root after applying updates, a map from leaf
+// index to new value, on top of the witness. Every updated index must be one
+// of the indices the witness was extracted for.
+// Nodes are hashed with the hasher of the tree the witness was extracted
+// from. A decoded witness looks its hasher up by fingerprint, so a hasher
+// other than PoseidonHasher must first be passed to RegisterHasher.
+func ApplyOnWitness(w *Witness, updates map[int]*big.Int) (*big.Int, error) {
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+	hasher := w.hasher
+	if hasher == nil {
+		var err error
+		if hasher, err = witnessHasher(w.Hasher); err != nil {
+			return nil, err
+		}
+	}
+	emptyHashes, err := emptyHashesWith(w.Depth, hasher, w.ZeroLeaf)
+	if err != nil {
+		return nil, err
+	}
+	for index, value := range updates {
+		if err := checkIndex(index, w.Depth); err != nil {
+			return nil, err
+		}
+		if err := checkValue(value); err != nil {
+			return nil, fmt.Errorf("update at index %d: %w", index, err)
+		}
+	}
+
+	leaves := make(map[string]*big.Int, len(w.Leaves))
+	touched := make(map[string]bool)
+	for index, value := range w.Leaves {
+		key := getPaddedBinaryString(index, w.Depth)
+		leaves[key] = value
+		for depth := 0; depth <= w.Depth; depth++ {
+			touched[key[:depth]] = true
+		}
+	}
+
+	for index, value := range updates {
+		key := getPaddedBinaryString(index, w.Depth)
+		if _, exists := leaves[key]; !exists {
+			return nil, fmt.Errorf("index %d is not covered by the witness", index)
+		}
+		leaves[key] = value
+	}
+
+	return w.hashPrefix("", leaves, touched, hasher, emptyHashes)
+}
+
+// witnessHasher returns the hasher with the given fingerprint, or
+// PoseidonHasher if it is empty.
+func witnessHasher(fingerprint string) (Hasher, error) {
+	if fingerprint == "" {
+		return PoseidonHasher, nil
+	}
+	return registeredHasher(fingerprint)
+}
+
+// validate returns ErrMalformedInput if the witness cannot be hashed, such as
+// a decoded witness with missing values or indices outside its depth.
+func (w *Witness) validate() error {
+	if w == nil {
+		return fmt.Errorf("%w: nil witness", ErrMalformedInput)
+	}
+	if w.Depth < 1 || w.Depth > maxSnapshotDepth {
+		return fmt.Errorf("%w: witness depth %d out of range", ErrMalformedInput, w.Depth)
+	}
+	if w.ZeroLeaf == nil {
+		return fmt.Errorf("%w: witness has no zero leaf", ErrMalformedInput)
+	}
+	for index, value := range w.Leaves {
+		if checkIndex(index, w.Depth) != nil || value == nil {
+			return fmt.Errorf("%w: invalid witness leaf at index %d", ErrMalformedInput, index)
+		}
+	}
+	for prefix, hash := range w.Nodes {
+		if len(prefix) >= w.Depth || strings.Trim(prefix, "01") != "" || hash == nil {
+			return fmt.Errorf("%w: invalid witness node at %q", ErrMalformedInput, prefix)
+		}
+	}
+	return nil
+}
+
+// hashPrefix computes the hash of the subtree at the given binary path
+// prefix, hashing nodes with hasher and taking the hashes of empty subtrees,
+// by height, from emptyHashes.
+func (w *Witness) hashPrefix(prefix string, leaves map[string]*big.Int, touched map[string]bool, hasher Hasher, emptyHashes []*big.Int) (*big.Int, error) {
+	if !touched[prefix] {
+		if hash, exists := w.Nodes[prefix]; exists {
+			return hash, nil
+		}
+		return emptyHashes[w.Depth-len(prefix)], nil
+	}
+	if len(prefix) == w.Depth {
+		return leaves[prefix], nil
+	}
+
+	left, err := w.hashPrefix(prefix+"0", leaves, touched, hasher, emptyHashes)
+	if err != nil {
+		return nil, err
+	}
+	right, err := w.hashPrefix(prefix+"1", leaves, touched, hasher, emptyHashes)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := hasher.Hash(left, right)
+	if err != nil {
+		return nil, fmt.Errorf("hashing node %q: %w", prefix, err)
+	}
+	return hash, nil
+}
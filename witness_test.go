@@ -0,0 +1,56 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractWitness(t *testing.T) {
+	tree := NewSparseMerkleTree(4, zeroLeaf)
+	for _, index := range []int{0, 3, 7, 12} {
+		tree.Insert(index, big.NewInt(int64(index+100)))
+	}
+
+	w, err := tree.ExtractWitness([]int{3, 5})
+	assert.NoError(t, err)
+	assert.Equal(t, tree.root.Data, w.Root())
+	assert.Len(t, w.Leaves, 2)
+	assert.Equal(t, zeroLeaf, w.Leaves[5])
+	assert.Less(t, len(w.Nodes), 2*tree.depth, "siblings shared by both paths are stored once")
+
+	updates := map[int]*big.Int{3: big.NewInt(33), 5: big.NewInt(55)}
+	postRoot, err := ApplyOnWitness(w, updates)
+	assert.NoError(t, err)
+
+	tree.Insert(3, big.NewInt(33))
+	tree.Insert(5, big.NewInt(55))
+	assert.Equal(t, tree.root.Data, postRoot)
+
+	_, err = ApplyOnWitness(w, map[int]*big.Int{7: big.NewInt(1)})
+	assert.Error(t, err)
+
+	_, err = tree.ExtractWitness([]int{3, 16})
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	_, err = tree.ExtractWitness([]int{-1})
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestExtractWitnessMigratedHasher(t *testing.T) {
+	tree := NewSparseMerkleTree(6, zeroLeaf)
+	for _, index := range []int{1, 20, 41} {
+		assert.NoError(t, tree.Insert(index, big.NewInt(int64(index))))
+	}
+	migrated, _, err := tree.MigrateHasher(sha256Hasher)
+	assert.NoError(t, err)
+
+	w, err := migrated.ExtractWitness([]int{20, 33})
+	assert.NoError(t, err)
+	assert.Equal(t, migrated.Root(), w.Root())
+
+	postRoot, err := ApplyOnWitness(w, map[int]*big.Int{33: big.NewInt(3)})
+	assert.NoError(t, err)
+	assert.NoError(t, migrated.Insert(33, big.NewInt(3)))
+	assert.Equal(t, migrated.Root(), postRoot)
+}